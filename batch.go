@@ -0,0 +1,95 @@
+package xuid
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+)
+
+// batchChunk is the number of UUIDs worth of entropy read at once when
+// filling a batch
+const batchChunk = 256
+
+// batchPool recycles the scratch buffers used to read entropy in chunks.
+// Unlike uuid.EnableRandPool, no entropy is retained between calls: each
+// buffer is refilled before use, so only the memory is reused.
+var batchPool = sync.Pool{
+	New: func() any {
+		return new([batchChunk * 16]byte)
+	},
+}
+
+// BatchGenerator is implemented by generators that can fill many XUIDs at
+// once more efficiently than repeated calls to New.
+type BatchGenerator interface {
+	Generator
+
+	// Fill sets every element of dst to a new XUID with the given prefix.
+	Fill(dst []XUID, prefix string) error
+}
+
+// Fill implements BatchGenerator. It reads entropy in large chunks rather
+// than once per ID, from the reader set with WithRand or from crypto/rand.
+//
+// The uuid package does not expose its entropy source, so without WithRand
+// Fill bypasses uuid.SetRand and the uuid randomness pool, which only affect
+// New. Use WithRand to obtain reproducible batches.
+func (g *RandomGenerator) Fill(dst []XUID, prefix string) error {
+	r := g.cfg.rand
+	if r == nil {
+		r = rand.Reader
+	}
+
+	buf := batchPool.Get().(*[batchChunk * 16]byte)
+	defer batchPool.Put(buf)
+
+	for len(dst) > 0 {
+		n := len(dst)
+		if n > batchChunk {
+			n = batchChunk
+		}
+		b := buf[:n*16]
+		if _, err := io.ReadFull(r, b); err != nil {
+			return err
+		}
+		for i := range dst[:n] {
+			x := &dst[i]
+			x.Prefix = prefix
+			copy(x.UUID[:], b[i*16:])
+			x.UUID[6] = (x.UUID[6] & 0x0f) | 0x40 // Version 4
+			x.UUID[8] = (x.UUID[8] & 0x3f) | 0x80 // Variant is 10
+		}
+		dst = dst[n:]
+	}
+	return nil
+}
+
+// FillRandom sets every element of dst to a new XUID with the given prefix,
+// using the package default generator. If the generator implements
+// BatchGenerator, which the default random generator does, entropy is read
+// in large chunks instead of once per ID.
+func FillRandom(dst []XUID, prefix string) error {
+	g := DefaultGenerator()
+	if bg, ok := g.(BatchGenerator); ok {
+		return bg.Fill(dst, prefix)
+	}
+	for i := range dst {
+		x, err := g.New(prefix)
+		if err != nil {
+			return err
+		}
+		dst[i] = x
+	}
+	return nil
+}
+
+// NewBatch returns n new XUIDs with the given prefix, generated in a single
+// allocation with FillRandom. It is intended for bulk operations minting
+// large numbers of IDs.
+func NewBatch(prefix string, n int) ([]XUID, error) {
+	res := make([]XUID, n)
+	if err := FillRandom(res, prefix); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
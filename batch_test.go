@@ -0,0 +1,105 @@
+package xuid
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewBatch(t *testing.T) {
+	const n = 1000 // more than one chunk
+	ids, err := NewBatch("test", n)
+	if err != nil {
+		t.Fatalf("NewBatch() error = %v", err)
+	}
+	if len(ids) != n {
+		t.Fatalf("NewBatch() returned %d IDs, want %d", len(ids), n)
+	}
+
+	seen := make(map[XUID]bool, n)
+	for _, x := range ids {
+		if x.Prefix != "test" {
+			t.Errorf("NewBatch() prefix = %q, want %q", x.Prefix, "test")
+		}
+		if x.UUID.Version() != 4 {
+			t.Errorf("NewBatch() version = %d, want 4", x.UUID.Version())
+		}
+		if seen[x] {
+			t.Errorf("NewBatch() returned %s twice", x)
+		}
+		seen[x] = true
+	}
+}
+
+func TestFillRandomMatchesNew(t *testing.T) {
+	// with the same entropy, Fill must produce the same IDs as New
+	g1 := NewRandomGenerator(WithRand(rand.New(rand.NewSource(3))))
+	g2 := NewRandomGenerator(WithRand(rand.New(rand.NewSource(3))))
+
+	ids := make([]XUID, 300)
+	if err := g1.Fill(ids, "test"); err != nil {
+		t.Fatalf("Fill() error = %v", err)
+	}
+	for i, x := range ids {
+		want, err := g2.New("test")
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if !x.Equals(want) {
+			t.Fatalf("Fill()[%d] = %s, want %s", i, x, want)
+		}
+	}
+}
+
+func TestFillRandomFallback(t *testing.T) {
+	// generators without batch support are called once per ID
+	prev := SetDefaultGenerator(NewV7Generator())
+	defer SetDefaultGenerator(prev)
+
+	ids, err := NewBatch("evt", 10)
+	if err != nil {
+		t.Fatalf("NewBatch() error = %v", err)
+	}
+	for _, x := range ids {
+		if x.UUID.Version() != 7 {
+			t.Errorf("NewBatch() version = %d, want 7", x.UUID.Version())
+		}
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = New("bench")
+	}
+}
+
+func BenchmarkNewBatch(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i += 1000 {
+		if _, err := NewBatch("bench", 1000); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFillRandom(b *testing.B) {
+	ids := make([]XUID, 1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i += len(ids) {
+		if err := FillRandom(ids, "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewRandPool(b *testing.B) {
+	// the uuid package randomness pool, the alternative to NewBatch
+	uuid.EnableRandPool()
+	defer uuid.DisableRandPool()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = New("bench")
+	}
+}
@@ -0,0 +1,77 @@
+package xuid
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/bits"
+)
+
+// bucketHash returns the first 64 bits of the SHA-256 hash of the salt, a
+// zero byte and the 16 bytes of the UUID of x
+func bucketHash(x XUID, salt string) uint64 {
+	h := sha256.New()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write(x.UUID[:])
+	var sum [sha256.Size]byte
+	return binary.BigEndian.Uint64(h.Sum(sum[:0]))
+}
+
+// bucketFraction returns the position of x in [0, 1) for the given salt
+func bucketFraction(x XUID, salt string) float64 {
+	return float64(bucketHash(x, salt)>>11) / (1 << 53)
+}
+
+// Bucket deterministically assigns x to a bucket between 0 and buckets-1.
+//
+// The assignment is derived from the SHA-256 hash of the salt, a zero byte
+// and the 16 bytes of the UUID, so it is uniform even for time ordered IDs
+// and independent between different salts. Use a distinct salt for each
+// feature or experiment so that the same IDs do not always end up together.
+// The prefix is not part of the hash. It returns -1 if buckets is not
+// positive, like AssignVariant without positive weights.
+func Bucket(x XUID, salt string, buckets int) int {
+	if buckets <= 0 {
+		return -1
+	}
+	hi, _ := bits.Mul64(bucketHash(x, salt), uint64(buckets))
+	return int(hi)
+}
+
+// InRollout reports whether x is part of a rollout enabled for the given
+// percentage (between 0 and 100) of IDs. Raising the percentage for the same
+// salt only adds IDs to the rollout, it never removes any.
+func InRollout(x XUID, salt string, percent float64) bool {
+	return bucketFraction(x, salt)*100 < percent
+}
+
+// AssignVariant deterministically assigns x to one of several variants of an
+// experiment, each variant getting a share of IDs proportional to its weight.
+// It returns the index of the variant, or -1 if no weight is positive.
+// Negative weights count as zero.
+func AssignVariant(x XUID, salt string, weights ...float64) int {
+	var total float64
+	for _, w := range weights {
+		if w > 0 {
+			total += w
+		}
+	}
+	if total <= 0 {
+		return -1
+	}
+
+	pos := bucketFraction(x, salt) * total
+	last := -1
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if pos < w {
+			return i
+		}
+		pos -= w
+		last = i
+	}
+	// rounding errors may leave pos slightly above the last weight
+	return last
+}
@@ -0,0 +1,106 @@
+package xuid
+
+import (
+	"testing"
+	"time"
+)
+
+// chiSquare returns the chi-square statistic of counts against a uniform
+// distribution
+func chiSquare(counts []int, total int) float64 {
+	expected := float64(total) / float64(len(counts))
+	var res float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		res += d * d / expected
+	}
+	return res
+}
+
+func TestBucketUniformity(t *testing.T) {
+	const n, buckets = 20000, 10
+
+	// time ordered IDs created within a few milliseconds share most bits
+	now := time.UnixMilli(1700000000000)
+	v7 := make([]XUID, n)
+	g := NewMonotonicGenerator(WithClock(func() time.Time { return now }))
+	for i := range v7 {
+		v7[i], _ = g.New("user")
+	}
+
+	sets := map[string][]XUID{
+		"random": testIDs(t, "user", n),
+		"v7":     v7,
+	}
+	for name, ids := range sets {
+		counts := make([]int, buckets)
+		for _, id := range ids {
+			b := Bucket(id, "feature-x", buckets)
+			if b < 0 || b >= buckets {
+				t.Fatalf("Bucket() = %d", b)
+			}
+			counts[b]++
+		}
+		// 27.88 is the critical value for 9 degrees of freedom at p=0.001
+		if chi := chiSquare(counts, n); chi > 27.88 {
+			t.Errorf("%s: Bucket() distribution %v is not uniform, chi-square = %.2f", name, counts, chi)
+		}
+
+		in := 0
+		for _, id := range ids {
+			if InRollout(id, "feature-x", 5) {
+				in++
+				if !InRollout(id, "feature-x", 10) {
+					t.Fatalf("%s: %s is in the 5%% rollout but not in the 10%% one", name, id)
+				}
+			}
+		}
+		if in < 880 || in > 1120 {
+			t.Errorf("%s: InRollout(5%%) selected %d IDs out of %d", name, in, n)
+		}
+	}
+}
+
+func TestBucketStable(t *testing.T) {
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	if b := Bucket(x, "salt", 1000); b != Bucket(x, "salt", 1000) {
+		t.Errorf("Bucket() is not deterministic")
+	}
+	y := x
+	y.Prefix = "user"
+	if Bucket(x, "salt", 1000) != Bucket(y, "salt", 1000) {
+		t.Errorf("Bucket() depends on the prefix")
+	}
+	if InRollout(x, "salt", 0) || !InRollout(x, "salt", 100) {
+		t.Errorf("InRollout() at 0%% or 100%% is wrong")
+	}
+}
+
+func TestBucketInvalid(t *testing.T) {
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	for _, n := range []int{0, -1, -100} {
+		if got := Bucket(x, "salt", n); got != -1 {
+			t.Errorf("Bucket(%d) = %d, want -1", n, got)
+		}
+	}
+}
+
+func TestAssignVariant(t *testing.T) {
+	ids := testIDs(t, "user", 20000)
+
+	counts := make([]int, 3)
+	for _, id := range ids {
+		v := AssignVariant(id, "exp-1", 1, 0, 3)
+		if v < 0 || v == 1 {
+			t.Fatalf("AssignVariant() = %d", v)
+		}
+		counts[v]++
+	}
+	if counts[0] < 4700 || counts[0] > 5300 {
+		t.Errorf("AssignVariant() assigned %d IDs out of 20000 to a 25%% variant", counts[0])
+	}
+
+	if v := AssignVariant(ids[0], "exp-1", 0, -1); v != -1 {
+		t.Errorf("AssignVariant() without positive weights = %d, want -1", v)
+	}
+}
@@ -0,0 +1,72 @@
+package xuid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkAlphabet holds the symbols of the ISO 7064 MOD 37,36 check character
+const checkAlphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// checkChar computes the ISO 7064 MOD 37,36 check character of s, ignoring
+// hyphens. Digits and lowercase letters take their value in checkAlphabet,
+// other bytes (which may appear in prefixes) are taken modulo 36.
+//
+// This system detects all single character substitutions and almost all
+// transpositions of adjacent characters.
+func checkChar(s string) byte {
+	const m = 36
+	p := m
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '-' {
+			continue
+		}
+		v := strings.IndexByte(checkAlphabet, c)
+		if v < 0 {
+			v = int(c) % m
+		}
+		t := (p + v) % m
+		if t == 0 {
+			t = m
+		}
+		p = (t * 2) % (m + 1)
+	}
+	return checkAlphabet[(m+1-p)%m]
+}
+
+// StringChecked returns the string form of the XUID followed by a check
+// character, such as shell-h4nu2n-zu3f-dmnn-kguv-6f643neiq. The check
+// character covers the prefix and the body, so that any single mistyped
+// character, and almost any swap of two adjacent characters, is detected by
+// ParseChecked instead of yielding another valid XUID.
+//
+// The checked form is one character longer than String and is not accepted
+// by Parse.
+func (x XUID) StringChecked() string {
+	s := x.String()
+	return s + string(checkChar(s))
+}
+
+// ParseChecked parses a XUID in the form returned by StringChecked. It
+// returns ErrBadChecksum if the check character does not match, which
+// usually means the input contains a typo.
+func ParseChecked(s string) (*XUID, error) {
+	s = strings.ToLower(s)
+	if len(s) < 31 {
+		return nil, fmt.Errorf("%w: input too short", ErrBadChecksum)
+	}
+	body, check := s[:len(s)-1], s[len(s)-1]
+	if checkChar(body) != check {
+		return nil, ErrBadChecksum
+	}
+	x, err := Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	if x.String() != body {
+		// the check character only covers the canonical form
+		return nil, fmt.Errorf("%w: not a canonical XUID", ErrBadChecksum)
+	}
+	return x, nil
+}
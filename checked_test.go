@@ -0,0 +1,78 @@
+package xuid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStringChecked(t *testing.T) {
+	x := MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	s := x.StringChecked()
+	if len(s) != len(x.String())+1 || s[:len(s)-1] != x.String() {
+		t.Fatalf("StringChecked() = %q", s)
+	}
+
+	got, err := ParseChecked(s)
+	if err != nil {
+		t.Fatalf("ParseChecked(%q) error = %v", s, err)
+	}
+	if !got.Equals(*x) {
+		t.Errorf("ParseChecked() = %s, want %s", got, x)
+	}
+
+	// uppercase input is accepted
+	if _, err := ParseChecked(strings.ToUpper(s)); err != nil {
+		t.Errorf("ParseChecked() of uppercase input error = %v", err)
+	}
+
+	// the existing format is unchanged
+	if _, err := Parse(s); err == nil {
+		t.Errorf("Parse() accepted the checked form")
+	}
+}
+
+func TestParseCheckedDetectsTypos(t *testing.T) {
+	var swaps, undetected int
+	for _, x := range append(testIDs(t, "user", 20), testIDs(t, "", 20)...) {
+		s := []byte(x.StringChecked())
+
+		// every single character substitution is detected
+		for i := range s {
+			if s[i] == '-' {
+				continue
+			}
+			orig := s[i]
+			for _, c := range []byte(checkAlphabet) {
+				if c == orig {
+					continue
+				}
+				s[i] = c
+				if y, err := ParseChecked(string(s)); err == nil {
+					t.Fatalf("ParseChecked(%q) accepted a typo of %s: %s", s, x, y)
+				}
+			}
+			s[i] = orig
+		}
+
+		// almost all swaps of adjacent characters are detected
+		for i := 0; i+1 < len(s); i++ {
+			if s[i] == s[i+1] || s[i] == '-' || s[i+1] == '-' {
+				continue
+			}
+			s[i], s[i+1] = s[i+1], s[i]
+			swaps++
+			if _, err := ParseChecked(string(s)); err == nil {
+				undetected++
+			}
+			s[i], s[i+1] = s[i+1], s[i]
+		}
+	}
+	if undetected*100 > swaps {
+		t.Errorf("ParseChecked() accepted %d transpositions out of %d", undetected, swaps)
+	}
+
+	if _, err := ParseChecked("shell"); !errors.Is(err, ErrBadChecksum) {
+		t.Errorf("ParseChecked() of short input error = %v, want %v", err, ErrBadChecksum)
+	}
+}
@@ -0,0 +1,73 @@
+package xuid
+
+import "sync"
+
+// clockSeq tracks the timestamp and sequence number of the last ID emitted by
+// a time based generator, keeping them strictly increasing even when many IDs
+// are created within one millisecond or when the clock steps backwards, and
+// persisting the high-water mark in the configured StateStore, if any.
+type clockSeq struct {
+	cfg    *generatorConfig
+	maxSeq uint64 // largest sequence number
+
+	mu       sync.Mutex
+	lastMs   int64  // timestamp of the last emitted ID
+	seq      uint64 // sequence number of the last emitted ID
+	loaded   bool   // whether the state store was loaded
+	reserved int64  // saved high-water mark
+}
+
+// next returns the timestamp and sequence number of the next ID. start is
+// the sequence number to use if the clock moved to a new millisecond.
+//
+// When the sequence is exhausted, or when the clock goes back in time, the
+// last timestamp keeps being used (moving it one millisecond ahead on
+// overflow) until the clock catches up.
+func (c *clockSeq) next(start uint64) (int64, uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cfg.state != nil && !c.loaded {
+		if err := c.load(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	ms := c.cfg.now().UnixMilli()
+	if ms > c.lastMs {
+		c.lastMs = ms
+		c.seq = start
+	} else if c.seq < c.maxSeq {
+		c.seq++
+	} else {
+		// sequence exhausted, borrow the next millisecond
+		c.lastMs++
+		c.seq = 0
+	}
+
+	if c.cfg.state != nil && c.lastMs >= c.reserved {
+		next := c.lastMs + c.cfg.reserveMs()
+		if err := c.cfg.state.Save(next); err != nil {
+			return 0, 0, err
+		}
+		c.reserved = next
+	}
+	return c.lastMs, c.seq, nil
+}
+
+// load reads the high-water mark from the state store so that the next ID
+// is emitted at or after it
+func (c *clockSeq) load() error {
+	hwm, err := c.cfg.state.Load()
+	if err != nil {
+		return err
+	}
+	if hwm > c.lastMs {
+		// act as if the sequence was exhausted right before the mark
+		c.lastMs = hwm - 1
+		c.seq = c.maxSeq
+	}
+	c.reserved = hwm
+	c.loaded = true
+	return nil
+}
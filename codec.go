@@ -0,0 +1,265 @@
+package xuid
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// listVersion is the version byte starting each encoded list
+const listVersion = 1
+
+// maxListPrefix is the maximum length of a prefix in an encoded list
+const maxListPrefix = 255
+
+// The list encoding is designed for large lists of XUIDs, typically sorted
+// or time ordered. A list is encoded as:
+//
+//	version byte (1)
+//	uvarint   number of prefix groups
+//	for each group, by increasing prefix:
+//	  uvarint   prefix length, followed by the prefix bytes
+//	  uvarint   number of XUIDs in the group
+//	  for each XUID, by increasing UUID:
+//	    uvarint128 difference with the previous UUID of the group (or zero)
+//
+// where uvarint128 is the 128 bits extension of the unsigned varint format
+// of encoding/binary. Consecutive version 7 UUIDs sharing a timestamp only
+// differ in their lower bits, and encode in fewer bytes than the 16 bytes of
+// a UUID. The order of the XUIDs is not preserved, duplicates are.
+
+// Encoder writes lists of XUIDs to an output stream using the compact list
+// encoding.
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewEncoder returns a new encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the encoding of ids to the stream. The slice is not modified.
+func (e *Encoder) Encode(ids []XUID) error {
+	var err error
+	e.buf, err = AppendList(e.buf[:0], ids)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(e.buf)
+	return err
+}
+
+// Decoder reads lists of XUIDs written by an Encoder from an input stream.
+type Decoder struct {
+	r io.ByteReader
+}
+
+// NewDecoder returns a new decoder reading from r. If r does not implement
+// io.ByteReader, the decoder buffers it and may read more data than needed.
+func NewDecoder(r io.Reader) *Decoder {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &Decoder{r: br}
+}
+
+// Decode reads the next list from the stream. The XUIDs are returned sorted
+// in the order defined by XUID.Compare. It returns io.EOF when there are no
+// more lists.
+func (d *Decoder) Decode() ([]XUID, error) {
+	v, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if v != listVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBadEncoding, v)
+	}
+
+	groups, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	var res []XUID
+	var lastPfx string
+	for g := uint64(0); g < groups; g++ {
+		n, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		if n > maxListPrefix {
+			return nil, fmt.Errorf("%w: prefix too long", ErrBadEncoding)
+		}
+		pfx := make([]byte, n)
+		for i := range pfx {
+			if pfx[i], err = d.byte(); err != nil {
+				return nil, err
+			}
+		}
+		if g > 0 && string(pfx) <= lastPfx {
+			return nil, fmt.Errorf("%w: prefix groups out of order", ErrBadEncoding)
+		}
+		lastPfx = string(pfx)
+
+		count, err := d.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		// do not trust count for allocations, the data may be truncated
+		if count < 4096 {
+			res = growXUIDs(res, int(count))
+		}
+		var hi, lo uint64
+		for i := uint64(0); i < count; i++ {
+			dhi, dlo, err := d.uvarint128()
+			if err != nil {
+				return nil, err
+			}
+			var c uint64
+			lo, c = bits.Add64(lo, dlo, 0)
+			hi, c = bits.Add64(hi, dhi, c)
+			if c != 0 {
+				return nil, fmt.Errorf("%w: UUID overflow", ErrBadEncoding)
+			}
+			x := XUID{Prefix: lastPfx}
+			binary.BigEndian.PutUint64(x.UUID[:8], hi)
+			binary.BigEndian.PutUint64(x.UUID[8:], lo)
+			res = append(res, x)
+		}
+	}
+	return res, nil
+}
+
+// byte reads one byte of a list, io.EOF becoming io.ErrUnexpectedEOF
+func (d *Decoder) byte() (byte, error) {
+	b, err := d.r.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return b, err
+}
+
+// uvarint reads an unsigned varint
+func (d *Decoder) uvarint() (uint64, error) {
+	hi, lo, err := d.uvarint128()
+	if err == nil && hi != 0 {
+		err = fmt.Errorf("%w: varint overflow", ErrBadEncoding)
+	}
+	return lo, err
+}
+
+// uvarint128 reads an unsigned 128 bits varint
+func (d *Decoder) uvarint128() (hi, lo uint64, err error) {
+	for shift := uint(0); ; shift += 7 {
+		b, err := d.byte()
+		if err != nil {
+			return 0, 0, err
+		}
+		v := uint64(b & 0x7f)
+		if shift >= 128 || (shift == 126 && v > 3) {
+			return 0, 0, fmt.Errorf("%w: varint overflow", ErrBadEncoding)
+		}
+		if shift < 64 {
+			lo |= v << shift
+			if shift > 57 {
+				hi |= v >> (64 - shift)
+			}
+		} else {
+			hi |= v << (shift - 64)
+		}
+		if b < 0x80 {
+			return hi, lo, nil
+		}
+	}
+}
+
+// growXUIDs makes room for n more elements in s
+func growXUIDs(s []XUID, n int) []XUID {
+	if cap(s)-len(s) >= n {
+		return s
+	}
+	res := make([]XUID, len(s), len(s)+n)
+	copy(res, s)
+	return res
+}
+
+// AppendList appends the compact list encoding of ids to dst and returns the
+// extended buffer. It fails if a prefix is longer than 255 bytes.
+func AppendList(dst []byte, ids []XUID) ([]byte, error) {
+	sorted := make([]XUID, len(ids))
+	copy(sorted, ids)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Compare(sorted[j]) < 0 })
+
+	groups := 0
+	for i, x := range sorted {
+		if len(x.Prefix) > maxListPrefix {
+			return dst, fmt.Errorf("%w: prefix %q too long", ErrBadEncoding, x.Prefix)
+		}
+		if i == 0 || x.Prefix != sorted[i-1].Prefix {
+			groups++
+		}
+	}
+
+	dst = append(dst, listVersion)
+	dst = binary.AppendUvarint(dst, uint64(groups))
+	for len(sorted) > 0 {
+		pfx := sorted[0].Prefix
+		n := 1
+		for n < len(sorted) && sorted[n].Prefix == pfx {
+			n++
+		}
+		dst = binary.AppendUvarint(dst, uint64(len(pfx)))
+		dst = append(dst, pfx...)
+		dst = binary.AppendUvarint(dst, uint64(n))
+
+		var phi, plo uint64
+		for _, x := range sorted[:n] {
+			hi := binary.BigEndian.Uint64(x.UUID[:8])
+			lo := binary.BigEndian.Uint64(x.UUID[8:])
+			dlo, b := bits.Sub64(lo, plo, 0)
+			dhi, _ := bits.Sub64(hi, phi, b)
+			dst = appendUvarint128(dst, dhi, dlo)
+			phi, plo = hi, lo
+		}
+		sorted = sorted[n:]
+	}
+	return dst, nil
+}
+
+// appendUvarint128 appends the varint encoding of the 128 bits value hi:lo
+func appendUvarint128(dst []byte, hi, lo uint64) []byte {
+	for hi != 0 || lo >= 0x80 {
+		dst = append(dst, byte(lo)|0x80)
+		lo = lo>>7 | hi<<57
+		hi >>= 7
+	}
+	return append(dst, byte(lo))
+}
+
+// MarshalList returns the compact list encoding of ids.
+func MarshalList(ids []XUID) ([]byte, error) {
+	return AppendList(nil, ids)
+}
+
+// UnmarshalList decodes a single list encoded by MarshalList. The XUIDs are
+// returned sorted in the order defined by XUID.Compare.
+func UnmarshalList(data []byte) ([]XUID, error) {
+	r := bytes.NewReader(data)
+	ids, err := NewDecoder(r).Decode()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: trailing data", ErrBadEncoding)
+	}
+	return ids, nil
+}
@@ -0,0 +1,177 @@
+package xuid
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// sortedCopy returns ids sorted by XUID.Compare
+func sortedCopy(ids []XUID) []XUID {
+	res := append([]XUID(nil), ids...)
+	// stable insertion sort, lists in tests are small
+	for i := 1; i < len(res); i++ {
+		for j := i; j > 0 && res[j-1].Compare(res[j]) > 0; j-- {
+			res[j-1], res[j] = res[j], res[j-1]
+		}
+	}
+	return res
+}
+
+func equalXUIDs(a, b []XUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestListRoundTrip(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	g := NewMonotonicGenerator(WithClock(func() time.Time { return now }))
+	var v7 []XUID
+	for i := 0; i < 1000; i++ {
+		x, _ := g.New("evt")
+		v7 = append(v7, x)
+	}
+
+	tests := []struct {
+		name string
+		ids  []XUID
+	}{
+		{"Empty", nil},
+		{"Single", []XUID{*MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")}},
+		{"Duplicates and prefixes", []XUID{
+			*MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"),
+			*MustParse("null-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa"),
+			*MustParse("h4nu2n-zu3f-dmnn-kguv-6f643nei"),
+			*MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"),
+			{Prefix: "max", UUID: maxUUID},
+		}},
+		{"Random", testIDs(t, "user", 500)},
+		{"V7", v7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalList(tt.ids)
+			if err != nil {
+				t.Fatalf("MarshalList() error = %v", err)
+			}
+			got, err := UnmarshalList(data)
+			if err != nil {
+				t.Fatalf("UnmarshalList() error = %v", err)
+			}
+			if want := sortedCopy(tt.ids); !equalXUIDs(got, want) {
+				t.Errorf("UnmarshalList() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	// time ordered IDs sharing a timestamp take much less than 16 bytes
+	data, _ := MarshalList(v7)
+	if len(data) > len(v7)*12 {
+		t.Errorf("MarshalList() of %d v7 IDs takes %d bytes", len(v7), len(data))
+	}
+}
+
+func TestEncoderStream(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	lists := [][]XUID{testIDs(t, "a", 10), nil, testIDs(t, "b", 300)}
+	for _, l := range lists {
+		if err := enc.Encode(l); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+
+	dec := NewDecoder(io.MultiReader(&buf)) // hide io.ByteReader
+	for i, l := range lists {
+		got, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("Decode() #%d error = %v", i, err)
+		}
+		if !equalXUIDs(got, sortedCopy(l)) {
+			t.Errorf("Decode() #%d returned wrong list", i)
+		}
+	}
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("Decode() at end of stream error = %v, want io.EOF", err)
+	}
+}
+
+func TestListErrors(t *testing.T) {
+	data, _ := MarshalList(testIDs(t, "user", 10))
+
+	if _, err := UnmarshalList(data[:len(data)-1]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("UnmarshalList() of truncated data error = %v", err)
+	}
+	if _, err := UnmarshalList(append(data, 0)); !errors.Is(err, ErrBadEncoding) {
+		t.Errorf("UnmarshalList() with trailing data error = %v", err)
+	}
+	if _, err := UnmarshalList([]byte{2, 0}); !errors.Is(err, ErrBadEncoding) {
+		t.Errorf("UnmarshalList() with bad version error = %v", err)
+	}
+	if _, err := MarshalList([]XUID{{Prefix: string(make([]byte, 256))}}); !errors.Is(err, ErrBadEncoding) {
+		t.Errorf("MarshalList() with long prefix error = %v", err)
+	}
+}
+
+func FuzzListRoundTrip(f *testing.F) {
+	f.Add([]byte{}, "user")
+	f.Add(bytes.Repeat([]byte{0xff}, 34), "")
+	f.Add([]byte("0123456789abcdef0123456789abcdef0"), "x")
+
+	f.Fuzz(func(t *testing.T, data []byte, prefix string) {
+		if len(prefix) > maxListPrefix {
+			return
+		}
+		// each 17 bytes chunk is a prefix selector followed by a UUID
+		var ids []XUID
+		for ; len(data) >= 17; data = data[17:] {
+			x := XUID{Prefix: []string{"", prefix, "user"}[int(data[0])%3]}
+			copy(x.UUID[:], data[1:17])
+			ids = append(ids, x)
+		}
+
+		enc, err := MarshalList(ids)
+		if err != nil {
+			t.Fatalf("MarshalList() error = %v", err)
+		}
+		got, err := UnmarshalList(enc)
+		if err != nil {
+			t.Fatalf("UnmarshalList() error = %v", err)
+		}
+		if !equalXUIDs(got, sortedCopy(ids)) {
+			t.Fatalf("round trip of %v returned %v", ids, got)
+		}
+	})
+}
+
+func FuzzUnmarshalList(f *testing.F) {
+	data, _ := MarshalList(testIDs(f, "user", 3))
+	f.Add(data)
+	f.Add([]byte{1, 1, 0, 1, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x04})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ids, err := UnmarshalList(data)
+		if err != nil {
+			return
+		}
+		// anything that decodes must encode back to a list decoding the same
+		enc, err := MarshalList(ids)
+		if err != nil {
+			t.Fatalf("MarshalList() error = %v", err)
+		}
+		again, err := UnmarshalList(enc)
+		if err != nil || !equalXUIDs(ids, again) {
+			t.Fatalf("re-encoding %v failed: %v", ids, err)
+		}
+	})
+}
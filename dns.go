@@ -0,0 +1,184 @@
+package xuid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxLabelLen is the maximum length of a DNS label
+const maxLabelLen = 63
+
+// isLabel reports whether s is a lowercase RFC 1123 label: 1 to 63 lowercase
+// letters, digits and hyphens, starting and ending with a letter or digit
+func isLabel(s string) bool {
+	if s == "" || len(s) > maxLabelLen || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// isHostLabel reports whether s, a valid label, is also usable as a host
+// name label: it must start with a letter, and hyphens in the third and
+// fourth positions are reserved for internationalized names (RFC 5891)
+func isHostLabel(s string) bool {
+	if s[0] < 'a' || s[0] > 'z' {
+		return false
+	}
+	return len(s) < 4 || s[2:4] != "--"
+}
+
+// labelName returns the canonical form of x if it is a valid label that
+// parses back to x
+func (x XUID) labelName() (string, error) {
+	s := x.String()
+	if !isLabel(s) {
+		return "", fmt.Errorf("%w: %q contains invalid characters", ErrBadLabel, s)
+	}
+	if y, err := Parse(s); err != nil || !y.Equals(x) {
+		return "", fmt.Errorf("%w: prefix %q does not round-trip", ErrBadLabel, x.Prefix)
+	}
+	return s, nil
+}
+
+// parseLabelName parses s, which must be a valid label, and checks that it
+// is the canonical form of the resulting XUID
+func parseLabelName(s string) (*XUID, error) {
+	x, err := Parse(s)
+	if err != nil || x.String() != s {
+		return nil, fmt.Errorf("%w: %q is not a XUID", ErrBadLabel, s)
+	}
+	return x, nil
+}
+
+// dnsEscape returns the letter-leading label used by DNSLabel for s, a
+// canonical form starting with a digit: an x followed by the prefix and its
+// separator, if any, and the body without hyphens. Canonical forms contain at
+// least four hyphens, so escaped labels with fewer hyphens can never be
+// confused with them. DNSLabel refuses the others, whose prefix contains
+// three hyphens or more.
+func dnsEscape(s string) string {
+	return "x" + s[:len(s)-30] + strings.ReplaceAll(s[len(s)-30:], "-", "")
+}
+
+// dnsUnescape reverses dnsEscape, returning false if l is not an escaped
+// label of a canonical form starting with a digit
+func dnsUnescape(l string) (string, bool) {
+	if len(l) < 1+bodyLen || l[0] != 'x' {
+		return "", false
+	}
+	pfx, body := l[1:len(l)-bodyLen], l[len(l)-bodyLen:]
+	if pfx != "" && (len(pfx) < 2 || pfx[len(pfx)-1] != '-') {
+		return "", false
+	}
+	if strings.IndexByte(body, '-') >= 0 {
+		return "", false
+	}
+	s := pfx + body[:6] + "-" + body[6:10] + "-" + body[10:14] + "-" + body[14:18] + "-" + body[18:]
+	if s[0] < '0' || s[0] > '9' {
+		return "", false
+	}
+	return s, true
+}
+
+// DNSLabel returns x as a DNS label suitable for a host name: lowercase,
+// starting with a letter, made of letters, digits and hyphens and not ending
+// with a hyphen.
+//
+// The label is the canonical form of x when it starts with a letter. Since
+// the base32 alphabet includes the digits 2 to 7, about one XUID without a
+// prefix out of five starts with a digit, as do XUIDs whose prefix starts
+// with one. These are encoded as an x followed by the prefix and its
+// separator, if any, and the body without its hyphens, for instance
+//
+//	x2aaaaaaaaaaaaaaaaaaaaaaaaa
+//
+// ParseDNSLabel accepts both forms. DNSLabel only returns ErrBadLabel when
+// the prefix does not survive String, that is when it is longer than 5
+// characters or contains anything other than lowercase letters, digits and
+// hyphens, when the label would have hyphens in its third and fourth
+// positions, which are reserved for internationalized names (RFC 5891), as
+// with the prefix "ab-", and when a prefix starting with a digit contains
+// three hyphens or more, as "1a---". Every label returned without error is
+// parsed back to x by ParseDNSLabel.
+func (x XUID) DNSLabel() (string, error) {
+	s, err := x.labelName()
+	if err != nil {
+		return "", err
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		s = dnsEscape(s)
+	}
+	if !isHostLabel(s) {
+		return "", fmt.Errorf("%w: %q is not a valid host name", ErrBadLabel, s)
+	}
+	if y, err := ParseDNSLabel(s); err != nil || !y.Equals(x) {
+		return "", fmt.Errorf("%w: %q does not round-trip", ErrBadLabel, s)
+	}
+	return s, nil
+}
+
+// ParseDNSLabel parses a label produced by DNSLabel. Since DNS is case
+// insensitive, the label may be in any case. It returns ErrBadLabel if s is
+// not a valid host name label or not a label produced by DNSLabel.
+func ParseDNSLabel(s string) (*XUID, error) {
+	l := toLowerASCII(s)
+	if !isLabel(l) || !isHostLabel(l) {
+		return nil, fmt.Errorf("%w: %q is not a valid host name", ErrBadLabel, s)
+	}
+	if strings.Count(l, "-") < 4 {
+		c, ok := dnsUnescape(l)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not a XUID", ErrBadLabel, s)
+		}
+		l = c
+	}
+	return parseLabelName(l)
+}
+
+// K8sName returns the canonical form of x as a Kubernetes object name, that
+// is a RFC 1123 label of at most maxLen characters. maxLen is capped to 63,
+// the limit of most resource types; some resources, such as CronJobs, accept
+// shorter names only.
+//
+// Names are never truncated: K8sName returns ErrBadLabel if the name would be
+// too long or would not parse back to x.
+func (x XUID) K8sName(maxLen int) (string, error) {
+	s, err := x.labelName()
+	if err != nil {
+		return "", err
+	}
+	if maxLen > maxLabelLen {
+		maxLen = maxLabelLen
+	}
+	if len(s) > maxLen {
+		return "", fmt.Errorf("%w: %q is longer than %d characters", ErrBadLabel, s, maxLen)
+	}
+	return s, nil
+}
+
+// ParseK8sName parses a Kubernetes object name produced by K8sName. Unlike
+// ParseDNSLabel, it only accepts lowercase names, as Kubernetes does.
+func ParseK8sName(s string) (*XUID, error) {
+	if !isLabel(s) {
+		return nil, fmt.Errorf("%w: %q is not a valid name", ErrBadLabel, s)
+	}
+	return parseLabelName(s)
+}
+
+// toLowerASCII returns s with ASCII letters mapped to lowercase, leaving
+// other bytes untouched so that lengths are preserved
+func toLowerASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
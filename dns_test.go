@@ -0,0 +1,118 @@
+package xuid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDNSLabel(t *testing.T) {
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	tests := []struct {
+		name string
+		x    XUID
+		want string
+		ok   bool
+	}{
+		{"Prefixed", x, "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei", true},
+		{"No prefix", XUID{UUID: x.UUID}, "h4nu2n-zu3f-dmnn-kguv-6f643nei", true},
+		{"Leading digit", *MustParse("2aaaaa-aaaa-aaaa-aaaa-aaaaaaaa"), "x2aaaaaaaaaaaaaaaaaaaaaaaaa", true},
+		{"Digit prefix", XUID{Prefix: "1ab", UUID: x.UUID}, "x1ab-h4nu2nzu3fdmnnkguv6f643nei", true},
+		{"Digit hyphen prefix", XUID{Prefix: "1-b", UUID: x.UUID}, "x1-b-h4nu2nzu3fdmnnkguv6f643nei", true},
+		{"Upper prefix", XUID{Prefix: "Shell", UUID: x.UUID}, "", false},
+		{"Long prefix", XUID{Prefix: "shells", UUID: x.UUID}, "", false},
+		{"Dot prefix", XUID{Prefix: "a.b", UUID: x.UUID}, "", false},
+		{"IDNA prefix", XUID{Prefix: "xn-", UUID: x.UUID}, "", false},
+		{"Escaped IDNA prefix", XUID{Prefix: "1-", UUID: x.UUID}, "", false},
+		{"Hyphens prefix 1a---", XUID{Prefix: "1a---", UUID: x.UUID}, "", false},
+		{"Hyphens prefix 11---", XUID{Prefix: "11---", UUID: x.UUID}, "", false},
+		{"Hyphens prefix 1-a--", XUID{Prefix: "1-a--", UUID: x.UUID}, "", false},
+		{"Hyphens prefix 1-1--", XUID{Prefix: "1-1--", UUID: x.UUID}, "", false},
+		{"Two hyphens prefix", XUID{Prefix: "1a--", UUID: x.UUID}, "x1a---h4nu2nzu3fdmnnkguv6f643nei", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.x.DNSLabel()
+			if !tt.ok {
+				if !errors.Is(err, ErrBadLabel) {
+					t.Errorf("DNSLabel() = %q, %v, want %v", got, err, ErrBadLabel)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("DNSLabel() = %q, %v, want %q", got, err, tt.want)
+			}
+			p, err := ParseDNSLabel(got)
+			if err != nil {
+				t.Fatalf("ParseDNSLabel(%q) error = %v", got, err)
+			}
+			if !p.Equals(tt.x) {
+				t.Errorf("ParseDNSLabel(%q) = %s, want %s", got, p, tt.x)
+			}
+		})
+	}
+}
+
+func TestParseDNSLabel(t *testing.T) {
+	want := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	if x, err := ParseDNSLabel("SHELL-H4NU2N-ZU3F-DMNN-KGUV-6F643NEI"); err != nil || !x.Equals(want) {
+		t.Errorf("ParseDNSLabel() = %v, %v, want %s", x, err, want)
+	}
+
+	invalid := []string{
+		"",
+		"2aaaaa-aaaa-aaaa-aaaa-aaaaaaaa",
+		"xaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"xab-h4nu2nzu3fdmnnkguv6f643nei",
+		"x1abh4nu2nzu3fdmnnkguv6f643nei",
+		"x2aaaaaaaaaaaaaaaaaaaaaaaaa-",
+		"shell-h4nu2n-zu3f-dmnn-kguv-6f643ne-",
+		"shell_h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"3f1b4d37-34d9-46c6-b546-a57c5f736d22",
+		"shell-h4nu2n-zu3f-dmnn-kguv-6f643nez",
+	}
+	for _, s := range invalid {
+		if _, err := ParseDNSLabel(s); !errors.Is(err, ErrBadLabel) {
+			t.Errorf("ParseDNSLabel(%q) error = %v, want %v", s, err, ErrBadLabel)
+		}
+	}
+}
+
+func TestDNSLabelAll(t *testing.T) {
+	ids := testIDs(t, "", 1000)
+	ids = append(ids, testIDs(t, "7z", 100)...)
+	for _, x := range ids {
+		l, err := x.DNSLabel()
+		if err != nil {
+			t.Fatalf("DNSLabel(%s) error = %v", x, err)
+		}
+		if !isLabel(l) || !isHostLabel(l) {
+			t.Fatalf("DNSLabel(%s) = %q, not a host name label", x, l)
+		}
+		if y, err := ParseDNSLabel(l); err != nil || !y.Equals(x) {
+			t.Fatalf("ParseDNSLabel(%q) = %v, %v, want %s", l, y, err, x)
+		}
+	}
+}
+
+func TestK8sName(t *testing.T) {
+	x := *MustParse("2aaaaa-aaaa-aaaa-aaaa-aaaaaaaa")
+	got, err := x.K8sName(63)
+	if err != nil || got != "2aaaaa-aaaa-aaaa-aaaa-aaaaaaaa" {
+		t.Fatalf("K8sName(63) = %q, %v", got, err)
+	}
+	if p, err := ParseK8sName(got); err != nil || !p.Equals(x) {
+		t.Errorf("ParseK8sName(%q) = %v, %v, want %s", got, p, err, x)
+	}
+
+	y := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	if got, err := y.K8sName(36); err != nil || got != y.String() {
+		t.Errorf("K8sName(36) = %q, %v, want %q", got, err, y.String())
+	}
+	if got, err := y.K8sName(35); !errors.Is(err, ErrBadLabel) {
+		t.Errorf("K8sName(35) = %q, %v, want %v", got, err, ErrBadLabel)
+	}
+	if _, err := ParseK8sName("SHELL-H4NU2N-ZU3F-DMNN-KGUV-6F643NEI"); !errors.Is(err, ErrBadLabel) {
+		t.Errorf("ParseK8sName() uppercase error = %v, want %v", err, ErrBadLabel)
+	}
+}
@@ -7,4 +7,59 @@ var (
 	// ErrBadPrefix is returned when a XUID's prefix doesn't match the expected value
 	// This is typically used by ParsePrefix to validate that an ID belongs to a specific entity type
 	ErrBadPrefix = errors.New("xuid: bad prefix")
+
+	// ErrStateLocked is returned by OpenFileStateStore when the state file is
+	// already in use by another store
+	ErrStateLocked = errors.New("xuid: state is locked by another process")
+
+	// ErrBadLayout is returned when a NodeLayout bit budget is invalid or a
+	// node ID does not fit in it
+	ErrBadLayout = errors.New("xuid: bad node layout")
+
+	// ErrNotNodeID is returned by NodeLayout.Inspect when the XUID was not
+	// generated by a NodeGenerator
+	ErrNotNodeID = errors.New("xuid: not a node XUID")
+
+	// ErrNoFreeNode is returned by NodeAllocator.Acquire when all node IDs
+	// are leased
+	ErrNoFreeNode = errors.New("xuid: no free node ID")
+
+	// ErrLeaseLost is returned when renewing a node lease that expired and
+	// was taken over by another worker
+	ErrLeaseLost = errors.New("xuid: node lease lost")
+
+	// ErrBadEncoding is returned when decoding an invalid XUID list
+	ErrBadEncoding = errors.New("xuid: bad list encoding")
+
+	// ErrBadFilter is returned when unmarshaling an invalid Filter or merging
+	// incompatible filters
+	ErrBadFilter = errors.New("xuid: bad filter")
+
+	// ErrNoMatch is returned by Index.Resolve when no XUID matches an
+	// abbreviation
+	ErrNoMatch = errors.New("xuid: no XUID matches abbreviation")
+
+	// ErrInvalidFormat is returned by ParseLenient when the input contains
+	// characters that cannot be part of a XUID
+	ErrInvalidFormat = errors.New("xuid: invalid format")
+
+	// ErrBadChecksum is returned by ParseChecked when the check character
+	// does not match the XUID
+	ErrBadChecksum = errors.New("xuid: bad check character")
+
+	// ErrBadURN is returned by ParseURN when the input is not a valid xuid
+	// or uuid URN
+	ErrBadURN = errors.New("xuid: bad URN")
+
+	// ErrBadPath is returned by ParsePath when a path does not match the
+	// layout produced by XUID.Path
+	ErrBadPath = errors.New("xuid: bad path")
+
+	// ErrBadLabel is returned when a XUID cannot be represented as, or parsed
+	// from, a DNS label or Kubernetes name
+	ErrBadLabel = errors.New("xuid: bad label")
+
+	// ErrNotPointer is returned by functions walking a value through
+	// reflection when they are not given a non-nil pointer
+	ErrNotPointer = errors.New("xuid: not a non-nil pointer")
 )
@@ -0,0 +1,151 @@
+package xuid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// filterVersion is the version byte starting a marshaled Filter
+const filterVersion = 1
+
+// Filter is a Bloom filter of XUIDs: a compact probabilistic set that can
+// tell for sure that a XUID was never added, but may wrongly report that a
+// XUID was added with a configurable false positive rate.
+//
+// The UUID bytes are used directly as hash input: the two halves of the UUID
+// are mixed with the MurmurHash3 finalizer, the prefix being folded into the
+// first half, and combined by double hashing. A Filter is not safe for
+// concurrent use.
+//
+// A Filter must be created with NewFilter or filled with UnmarshalBinary.
+// The zero value is an empty filter that cannot hold anything: Has always
+// returns false, and Add panics.
+type Filter struct {
+	bits []uint64
+	m    uint64 // number of bits
+	k    uint64 // number of hash functions
+}
+
+// NewFilter returns a filter sized to hold n XUIDs with the given false
+// positive rate, between 0 and 1 exclusive.
+func NewFilter(n int, fpRate float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return newFilter(m, k)
+}
+
+// newFilter returns an empty filter of m bits using k hash functions
+func newFilter(m, k uint64) *Filter {
+	if m < 64 {
+		m = 64
+	}
+	return &Filter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// hashes returns the two base hashes of x for double hashing
+func (f *Filter) hashes(x XUID) (uint64, uint64) {
+	h1 := binary.BigEndian.Uint64(x.UUID[:8])
+	h2 := binary.BigEndian.Uint64(x.UUID[8:])
+	if x.Prefix != "" {
+		h := fnv.New64a()
+		h.Write([]byte(x.Prefix))
+		h1 ^= h.Sum64()
+	}
+	return mix64(h1), mix64(h2) | 1
+}
+
+// Add adds x to the filter. It panics if f was not created with NewFilter
+// or UnmarshalBinary.
+func (f *Filter) Add(x XUID) {
+	if f.m == 0 {
+		panic("xuid: Add on an uninitialized Filter")
+	}
+	h1, h2 := f.hashes(x)
+	for i := uint64(0); i < f.k; i++ {
+		p := (h1 + i*h2) % f.m
+		f.bits[p/64] |= 1 << (p % 64)
+	}
+}
+
+// Has reports whether x may have been added to the filter. A false result
+// means x was never added.
+func (f *Filter) Has(x XUID) bool {
+	if f.m == 0 {
+		return false
+	}
+	h1, h2 := f.hashes(x)
+	for i := uint64(0); i < f.k; i++ {
+		p := (h1 + i*h2) % f.m
+		if f.bits[p/64]&(1<<(p%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge adds all the XUIDs of o to f. Both filters must have been created
+// with the same parameters.
+func (f *Filter) Merge(o *Filter) error {
+	if f.m != o.m || f.k != o.k {
+		return fmt.Errorf("%w: cannot merge filters with different parameters", ErrBadFilter)
+	}
+	for i, v := range o.bits {
+		f.bits[i] |= v
+	}
+	return nil
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface for
+// Filter.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	if f.m == 0 {
+		return nil, fmt.Errorf("%w: uninitialized filter", ErrBadFilter)
+	}
+	res := make([]byte, 0, 1+2*binary.MaxVarintLen64+len(f.bits)*8)
+	res = append(res, filterVersion)
+	res = binary.AppendUvarint(res, f.m)
+	res = binary.AppendUvarint(res, f.k)
+	for _, v := range f.bits {
+		res = binary.LittleEndian.AppendUint64(res, v)
+	}
+	return res, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface for
+// Filter.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != filterVersion {
+		return fmt.Errorf("%w: unsupported version", ErrBadFilter)
+	}
+	data = data[1:]
+	m, n := binary.Uvarint(data)
+	if n <= 0 {
+		return fmt.Errorf("%w: invalid size", ErrBadFilter)
+	}
+	data = data[n:]
+	k, n := binary.Uvarint(data)
+	if n <= 0 || k < 1 || k > 64 {
+		return fmt.Errorf("%w: invalid number of hash functions", ErrBadFilter)
+	}
+	data = data[n:]
+	if m < 64 || uint64(len(data)) != (m+63)/64*8 {
+		return fmt.Errorf("%w: size mismatch", ErrBadFilter)
+	}
+
+	*f = *newFilter(m, k)
+	for i := range f.bits {
+		f.bits[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package xuid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	const n = 5000
+	ids := testIDs(t, "user", 2*n)
+	added, others := ids[:n], ids[n:]
+
+	f := NewFilter(n, 0.01)
+	for _, x := range added {
+		f.Add(x)
+	}
+	for _, x := range added {
+		if !f.Has(x) {
+			t.Fatalf("Has(%s) = false for an added XUID", x)
+		}
+	}
+
+	fp := 0
+	for _, x := range others {
+		if f.Has(x) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / n; rate > 0.02 {
+		t.Errorf("false positive rate = %.4f, want about 0.01", rate)
+	}
+
+	// same UUID, different prefix
+	y := added[0]
+	y.Prefix = "doc"
+	if f.Has(y) && f.Has(XUID{UUID: y.UUID}) {
+		t.Errorf("Has() ignores the prefix")
+	}
+}
+
+func TestFilterV7(t *testing.T) {
+	// time ordered IDs share most of their first half
+	now := time.UnixMilli(1700000000000)
+	g := NewMonotonicGenerator(WithClock(func() time.Time { return now }))
+	f := NewFilter(2000, 0.01)
+	for i := 0; i < 2000; i++ {
+		x, _ := g.New("evt")
+		f.Add(x)
+	}
+	fp := 0
+	for i := 0; i < 2000; i++ {
+		x, _ := g.New("evt")
+		if f.Has(x) {
+			fp++
+		}
+	}
+	if fp > 40 {
+		t.Errorf("%d false positives out of 2000 for v7 IDs", fp)
+	}
+}
+
+func TestFilterMergeMarshal(t *testing.T) {
+	ids := testIDs(t, "user", 200)
+	a, b := NewFilter(200, 0.01), NewFilter(200, 0.01)
+	for _, x := range ids[:100] {
+		a.Add(x)
+	}
+	for _, x := range ids[100:] {
+		b.Add(x)
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	var c Filter
+	if err := c.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	for _, x := range ids {
+		if !c.Has(x) {
+			t.Fatalf("Has(%s) = false after merge and unmarshal", x)
+		}
+	}
+
+	if err := a.Merge(NewFilter(10, 0.1)); !errors.Is(err, ErrBadFilter) {
+		t.Errorf("Merge() of different filters error = %v, want %v", err, ErrBadFilter)
+	}
+	if err := c.UnmarshalBinary(data[:len(data)-1]); !errors.Is(err, ErrBadFilter) {
+		t.Errorf("UnmarshalBinary() of truncated data error = %v, want %v", err, ErrBadFilter)
+	}
+}
+
+func TestFilterZero(t *testing.T) {
+	var f Filter
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	if f.Has(x) {
+		t.Errorf("Has() on a zero Filter = true, want false")
+	}
+	if _, err := f.MarshalBinary(); !errors.Is(err, ErrBadFilter) {
+		t.Errorf("MarshalBinary() on a zero Filter error = %v, want %v", err, ErrBadFilter)
+	}
+	if err := f.Merge(NewFilter(10, 0.01)); !errors.Is(err, ErrBadFilter) {
+		t.Errorf("Merge() into a zero Filter error = %v, want %v", err, ErrBadFilter)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Add() on a zero Filter did not panic")
+		}
+	}()
+	f.Add(x)
+}
@@ -0,0 +1,79 @@
+package xuid
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format implements fmt.Formatter. The supported verbs are:
+//
+//	%s, %v  canonical form, as returned by String
+//	%q      canonical form, double-quoted (back-quoted with %#q)
+//	%x, %X  the UUID as 32 hexadecimal digits, lower or upper case
+//	%+v     debug form: {Prefix:shell UUID:3f1b4d37-... Version:4}
+//	%#v     Go syntax: *xuid.MustParse("shell-h4nu2n-...")
+//
+// Width and the '-' flag are honoured for all verbs, and precision truncates
+// the output of %s and %v.
+func (x XUID) Format(f fmt.State, verb rune) {
+	var s string
+	switch verb {
+	case 'v':
+		switch {
+		case f.Flag('#'):
+			s = x.goString()
+		case f.Flag('+'):
+			s = fmt.Sprintf("{Prefix:%s UUID:%s Version:%d}", x.Prefix, x.UUID, x.UUID.Version())
+		default:
+			s = x.String()
+		}
+		if p, ok := f.Precision(); ok && len(s) > p {
+			s = s[:p]
+		}
+	case 's':
+		s = x.String()
+		if p, ok := f.Precision(); ok && len(s) > p {
+			s = s[:p]
+		}
+	case 'q':
+		if f.Flag('#') {
+			s = "`" + x.String() + "`"
+		} else {
+			s = strconv.Quote(x.String())
+		}
+	case 'x':
+		s = hex.EncodeToString(x.UUID[:])
+	case 'X':
+		s = strings.ToUpper(hex.EncodeToString(x.UUID[:]))
+	default:
+		s = "%!" + string(verb) + "(xuid.XUID=" + x.String() + ")"
+	}
+	writePadded(f, s)
+}
+
+// goString returns a Go expression evaluating to x. MustParse is used when
+// the canonical form parses back to x, a composite literal otherwise.
+func (x XUID) goString() string {
+	s := x.String()
+	if y, err := Parse(s); err == nil && y.Equals(x) {
+		return "*xuid.MustParse(" + strconv.Quote(s) + ")"
+	}
+	return "xuid.XUID{Prefix:" + strconv.Quote(x.Prefix) + ", UUID:uuid.MustParse(" + strconv.Quote(x.UUID.String()) + ")}"
+}
+
+// writePadded writes s to f, padded with spaces to the width of f
+func writePadded(f fmt.State, s string) {
+	w, ok := f.Width()
+	if !ok || w <= len(s) {
+		f.Write([]byte(s))
+		return
+	}
+	pad := strings.Repeat(" ", w-len(s))
+	if f.Flag('-') {
+		f.Write([]byte(s + pad))
+	} else {
+		f.Write([]byte(pad + s))
+	}
+}
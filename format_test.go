@@ -0,0 +1,54 @@
+package xuid
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFormat(t *testing.T) {
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{"%v", "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{"%q", `"shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"`},
+		{"%#q", "`shell-h4nu2n-zu3f-dmnn-kguv-6f643nei`"},
+		{"%x", "3f1b4d3734d946c6b546a57c5f736d22"},
+		{"%X", "3F1B4D3734D946C6B546A57C5F736D22"},
+		{"%+v", "{Prefix:shell UUID:3f1b4d37-34d9-46c6-b546-a57c5f736d22 Version:4}"},
+		{"%#v", `*xuid.MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")`},
+		{"%40s|", "    shell-h4nu2n-zu3f-dmnn-kguv-6f643nei|"},
+		{"%-40s|", "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei    |"},
+		{"%.12s", "shell-h4nu2n"},
+		{"%d", "%!d(xuid.XUID=shell-h4nu2n-zu3f-dmnn-kguv-6f643nei)"},
+	}
+
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, x); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+		if got := fmt.Sprintf(tt.format, &x); got != tt.want {
+			t.Errorf("Sprintf(%q) with pointer = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestFormatGoSyntax(t *testing.T) {
+	u := uuid.MustParse("3f1b4d37-34d9-46c6-b546-a57c5f736d22")
+	tests := []struct {
+		x    XUID
+		want string
+	}{
+		{XUID{UUID: u}, `*xuid.MustParse("h4nu2n-zu3f-dmnn-kguv-6f643nei")`},
+		{XUID{Prefix: "Shell", UUID: u}, `xuid.XUID{Prefix:"Shell", UUID:uuid.MustParse("3f1b4d37-34d9-46c6-b546-a57c5f736d22")}`},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf("%#v", tt.x); got != tt.want {
+			t.Errorf("Sprintf(%%#v) = %q, want %q", got, tt.want)
+		}
+	}
+}
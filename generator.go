@@ -0,0 +1,215 @@
+package xuid
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Generator is the interface implemented by XUID generation strategies.
+// New returns a new XUID carrying the given prefix.
+//
+// Implementations must be safe for concurrent use, since the package default
+// generator is shared by every caller of New and NewRandom.
+type Generator interface {
+	New(prefix string) (XUID, error)
+}
+
+// GeneratorOption configures a generator created by one of the
+// NewXxxGenerator constructors.
+type GeneratorOption func(*generatorConfig)
+
+// generatorConfig holds the injectable dependencies shared by the generators
+type generatorConfig struct {
+	// rand is the entropy source, nil meaning the uuid package default
+	rand io.Reader
+	// clock returns the current time, nil meaning time.Now
+	clock func() time.Time
+	// state persists the high-water mark of time based generators
+	state StateStore
+	// reserve is how far ahead of the clock the state is saved
+	reserve time.Duration
+}
+
+// WithRand sets the entropy source used by a generator.
+// Passing a deterministic reader makes the generated IDs reproducible, which
+// is useful for golden tests and simulations.
+//
+// Readers are not required to be safe for concurrent use: the generators
+// serialize their reads when a custom reader is set.
+func WithRand(r io.Reader) GeneratorOption {
+	return func(c *generatorConfig) {
+		c.rand = r
+	}
+}
+
+// WithClock sets the function used by time based generators to obtain
+// the current time. It defaults to time.Now.
+func WithClock(clock func() time.Time) GeneratorOption {
+	return func(c *generatorConfig) {
+		c.clock = clock
+	}
+}
+
+// newGeneratorConfig applies the given options on top of the defaults
+func newGeneratorConfig(opts []GeneratorOption) generatorConfig {
+	var c generatorConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.rand != nil {
+		c.rand = &lockedReader{r: c.rand}
+	}
+	return c
+}
+
+// now returns the current time according to the configured clock
+func (c *generatorConfig) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock()
+}
+
+// read fills b with entropy from the configured source
+func (c *generatorConfig) read(b []byte) error {
+	r := c.rand
+	if r == nil {
+		r = rand.Reader
+	}
+	_, err := io.ReadFull(r, b)
+	return err
+}
+
+// RandomGenerator generates version 4 (random) XUIDs.
+// It is the default generator of the package.
+type RandomGenerator struct {
+	cfg generatorConfig
+}
+
+// NewRandomGenerator returns a generator producing version 4 XUIDs.
+// Without WithRand, entropy comes from uuid.NewRandom and therefore honours
+// uuid.SetRand and uuid.EnableRandPool.
+func NewRandomGenerator(opts ...GeneratorOption) *RandomGenerator {
+	return &RandomGenerator{cfg: newGeneratorConfig(opts)}
+}
+
+// New implements Generator
+func (g *RandomGenerator) New(prefix string) (XUID, error) {
+	var u uuid.UUID
+	var err error
+	if g.cfg.rand == nil {
+		u, err = uuid.NewRandom()
+	} else {
+		u, err = uuid.NewRandomFromReader(g.cfg.rand)
+	}
+	if err != nil {
+		return XUID{}, err
+	}
+	return XUID{Prefix: prefix, UUID: u}, nil
+}
+
+// V7Generator generates version 7 (time ordered) XUIDs.
+// Version 7 UUIDs start with a 48 bits millisecond timestamp, which makes
+// them sort roughly by creation time.
+type V7Generator struct {
+	cfg generatorConfig
+}
+
+// NewV7Generator returns a generator producing version 7 XUIDs, using the
+// clock set with WithClock for the timestamp part and the reader set with
+// WithRand (crypto/rand by default) for the random part.
+func NewV7Generator(opts ...GeneratorOption) *V7Generator {
+	return &V7Generator{cfg: newGeneratorConfig(opts)}
+}
+
+// New implements Generator
+func (g *V7Generator) New(prefix string) (XUID, error) {
+	var u uuid.UUID
+	if err := g.cfg.read(u[:]); err != nil {
+		return XUID{}, err
+	}
+	setV7(&u, g.cfg.now().UnixMilli())
+	return XUID{Prefix: prefix, UUID: u}, nil
+}
+
+// setV7 stores the millisecond timestamp ms in u and sets the version and
+// variant bits of a version 7 UUID. Other bits are left untouched.
+func setV7(u *uuid.UUID, ms int64) {
+	u[0] = byte(ms >> 40)
+	u[1] = byte(ms >> 32)
+	u[2] = byte(ms >> 24)
+	u[3] = byte(ms >> 16)
+	u[4] = byte(ms >> 8)
+	u[5] = byte(ms)
+	u[6] = 0x70 | (u[6] & 0x0f)
+	u[8] = 0x80 | (u[8] & 0x3f)
+}
+
+// DerivedGenerator generates deterministic XUIDs from keys, the same way
+// FromKeyPrefix does.
+type DerivedGenerator struct {
+	key func(prefix string) string
+}
+
+// NewDerivedGenerator returns a generator deriving each XUID from the key
+// returned by the key function for the requested prefix. The resulting XUID
+// is identical to the one returned by FromKeyPrefix(key(prefix), prefix).
+func NewDerivedGenerator(key func(prefix string) string) *DerivedGenerator {
+	return &DerivedGenerator{key: key}
+}
+
+// New implements Generator
+func (g *DerivedGenerator) New(prefix string) (XUID, error) {
+	x, err := FromKeyPrefix(g.key(prefix), prefix)
+	if err != nil {
+		return XUID{}, err
+	}
+	return *x, nil
+}
+
+// generatorBox allows storing a Generator interface in an atomic.Value,
+// which requires values of a consistent concrete type
+type generatorBox struct {
+	g Generator
+}
+
+// defaultGenerator holds the generator used by New and NewRandom
+var defaultGenerator atomic.Value
+
+func init() {
+	defaultGenerator.Store(generatorBox{NewRandomGenerator()})
+}
+
+// DefaultGenerator returns the generator currently used by New and NewRandom.
+func DefaultGenerator() Generator {
+	return defaultGenerator.Load().(generatorBox).g
+}
+
+// SetDefaultGenerator replaces the generator used by New and NewRandom and
+// returns the previous one, so it can be restored later. Passing nil restores
+// a random generator.
+func SetDefaultGenerator(g Generator) Generator {
+	if g == nil {
+		g = NewRandomGenerator()
+	}
+	return defaultGenerator.Swap(generatorBox{g}).(generatorBox).g
+}
+
+// lockedReader serializes reads on a reader that may not be safe for
+// concurrent use, such as a math/rand source. Each Read fills the whole
+// buffer so that concurrent callers never interleave partial reads.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+func (l *lockedReader) Read(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return io.ReadFull(l.r, b)
+}
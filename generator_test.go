@@ -0,0 +1,109 @@
+package xuid
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRandomGeneratorWithRand(t *testing.T) {
+	g1 := NewRandomGenerator(WithRand(rand.New(rand.NewSource(42))))
+	g2 := NewRandomGenerator(WithRand(rand.New(rand.NewSource(42))))
+
+	for i := 0; i < 10; i++ {
+		x1, err := g1.New("test")
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		x2, err := g2.New("test")
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if !x1.Equals(x2) {
+			t.Errorf("New() with same seed produced %s and %s", x1, x2)
+		}
+		if x1.UUID.Version() != 4 {
+			t.Errorf("New() version = %d, want 4", x1.UUID.Version())
+		}
+		if x1.Prefix != "test" {
+			t.Errorf("New() prefix = %q, want %q", x1.Prefix, "test")
+		}
+	}
+}
+
+func TestV7GeneratorClock(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	g := NewV7Generator(WithClock(func() time.Time { return now }), WithRand(rand.New(rand.NewSource(1))))
+
+	x, err := g.New("evt")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if x.UUID.Version() != 7 {
+		t.Errorf("New() version = %d, want 7", x.UUID.Version())
+	}
+	if x.UUID.Variant() != uuid.RFC4122 {
+		t.Errorf("New() variant = %s, want %s", x.UUID.Variant(), uuid.RFC4122)
+	}
+
+	var ms int64
+	for _, b := range x.UUID[:6] {
+		ms = ms<<8 | int64(b)
+	}
+	if ms != now.UnixMilli() {
+		t.Errorf("New() timestamp = %d, want %d", ms, now.UnixMilli())
+	}
+
+	// a later clock must produce a greater ID
+	now = now.Add(time.Millisecond)
+	y, err := g.New("evt")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if string(y.UUID[:]) <= string(x.UUID[:]) {
+		t.Errorf("New() %s is not greater than %s", y, x)
+	}
+}
+
+func TestDerivedGenerator(t *testing.T) {
+	g := NewDerivedGenerator(func(prefix string) string { return "key-" + prefix })
+
+	x, err := g.New("res")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	want, _ := FromKeyPrefix("key-res", "res")
+	if !x.Equals(*want) {
+		t.Errorf("New() = %s, want %s", x, want)
+	}
+}
+
+func TestSetDefaultGenerator(t *testing.T) {
+	g := NewDerivedGenerator(func(prefix string) string { return "fixed" })
+	prev := SetDefaultGenerator(g)
+	defer SetDefaultGenerator(prev)
+
+	if DefaultGenerator() != Generator(g) {
+		t.Errorf("DefaultGenerator() did not return the generator that was set")
+	}
+
+	want, _ := FromKeyPrefix("fixed", "test")
+	if x := New("test"); !x.Equals(*want) {
+		t.Errorf("New() = %s, want %s", x, want)
+	}
+	x, err := NewRandom("test")
+	if err != nil {
+		t.Fatalf("NewRandom() error = %v", err)
+	}
+	if !x.Equals(*want) {
+		t.Errorf("NewRandom() = %s, want %s", x, want)
+	}
+
+	// nil restores a random generator
+	SetDefaultGenerator(nil)
+	if _, ok := DefaultGenerator().(*RandomGenerator); !ok {
+		t.Errorf("SetDefaultGenerator(nil) installed %T, want *RandomGenerator", DefaultGenerator())
+	}
+}
@@ -0,0 +1,25 @@
+package xuid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"github.com/google/uuid"
+)
+
+// keyedUUID derives a version 8 UUID from x using HMAC-SHA256 keyed with key
+// over the prefix, a zero byte and the 16 bytes of the UUID. The result
+// cannot be linked back to x without the key, and the same UUID under two
+// different prefixes yields unrelated values.
+func keyedUUID(key []byte, x XUID) uuid.UUID {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(x.Prefix))
+	m.Write([]byte{0})
+	m.Write(x.UUID[:])
+	var sum [sha256.Size]byte
+	var u uuid.UUID
+	copy(u[:], m.Sum(sum[:0]))
+	u[6] = 0x80 | (u[6] & 0x0f)
+	u[8] = 0x80 | (u[8] & 0x3f)
+	return u
+}
@@ -0,0 +1,233 @@
+package xuid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CorrectionKind identifies the kind of fix applied by ParseLenient.
+type CorrectionKind int
+
+const (
+	// CorrectionConfusable is a character outside of the base32 alphabet
+	// replaced by the letter it is commonly confused with
+	CorrectionConfusable CorrectionKind = iota + 1
+
+	// CorrectionSeparator is a hyphen removed from or inserted in the input
+	CorrectionSeparator
+
+	// CorrectionWhitespace is a whitespace character removed from the input
+	CorrectionWhitespace
+)
+
+// String returns a name for the kind of correction
+func (k CorrectionKind) String() string {
+	switch k {
+	case CorrectionConfusable:
+		return "confusable"
+	case CorrectionSeparator:
+		return "separator"
+	case CorrectionWhitespace:
+		return "whitespace"
+	default:
+		return fmt.Sprintf("CorrectionKind(%d)", int(k))
+	}
+}
+
+// Correction describes a fix applied to the input of ParseLenient, so that a
+// user interface can ask for confirmation. From is the original text at byte
+// Offset of the input, To its replacement: From is empty for insertions and
+// To is empty for removals.
+type Correction struct {
+	Kind   CorrectionKind
+	Offset int
+	From   string
+	To     string
+}
+
+// String returns a human readable description of the correction
+func (c Correction) String() string {
+	switch {
+	case c.From == "":
+		return fmt.Sprintf("inserted %q at offset %d", c.To, c.Offset)
+	case c.To == "":
+		return fmt.Sprintf("removed %q at offset %d", c.From, c.Offset)
+	default:
+		return fmt.Sprintf("replaced %q with %q at offset %d", c.From, c.To, c.Offset)
+	}
+}
+
+// confusables maps characters that are not part of the base32 alphabet to the
+// letters they are commonly mistaken for, by order of preference
+var confusables = map[byte]string{
+	'0': "o",
+	'1': "li",
+	'8': "b",
+}
+
+// isBase32 reports whether c is a lowercase character of the XUID alphabet
+func isBase32(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= '2' && c <= '7')
+}
+
+// isLastBase32 reports whether c is valid as the last character of a
+// canonical body, which only holds 3 bits
+func isLastBase32(c byte) bool {
+	return strings.IndexByte("aeimquy4", c) >= 0
+}
+
+// ParseLenient parses a XUID typed or read by a human. On top of what Parse
+// accepts, it:
+//
+//   - ignores whitespace anywhere in the input
+//   - tolerates missing, extra or misplaced hyphens
+//   - replaces characters outside of the base32 alphabet with the letters
+//     they are commonly confused with: 0 with o, 1 with l (or i when l is not
+//     valid) and 8 with b
+//   - accepts the URN forms parsed by ParseURN
+//
+// It returns the parsed XUID along with the list of corrections that were
+// applied, sorted by offset, so that the caller can ask the user to confirm
+// them. Changes of case of the body are not reported as corrections. The
+// prefix is returned as typed, so that ParseLenient and Parse return equal
+// XUIDs for any input Parse accepts.
+func ParseLenient(s string) (*XUID, []Correction, error) {
+	if t := strings.TrimSpace(s); len(t) >= 4 && strings.EqualFold(t[:4], "urn:") {
+		return parseLenientURN(s, t)
+	}
+
+	var corr []Correction
+	var sig []byte // significant characters
+	var offs []int // offset of each significant character in s
+	// hyphens found in s, with the number of significant characters before them
+	var hyphs []struct{ off, count int }
+
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			corr = append(corr, Correction{Kind: CorrectionWhitespace, Offset: i, From: s[i : i+size]})
+		case r == '-':
+			hyphs = append(hyphs, struct{ off, count int }{i, len(sig)})
+		case r < utf8.RuneSelf:
+			sig = append(sig, byte(r))
+			offs = append(offs, i)
+		default:
+			return nil, nil, fmt.Errorf("%w: invalid character %q at offset %d", ErrInvalidFormat, r, i)
+		}
+		i += size
+	}
+
+	pfxLn := len(sig) - bodyLen
+	if pfxLn < 0 || pfxLn > 5 {
+		// not a XUID, try as a UUID without the whitespace
+		var b strings.Builder
+		for _, r := range s {
+			if !unicode.IsSpace(r) {
+				b.WriteRune(r)
+			}
+		}
+		x, err := ParseUUID(b.String(), "")
+		if err != nil {
+			return nil, nil, err
+		}
+		return x, corr, nil
+	}
+
+	// map confusable characters of the body, which is case insensitive; the
+	// prefix is kept as is, like Parse does
+	for i := pfxLn; i < len(sig); i++ {
+		c := sig[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+			sig[i] = c
+		}
+		if isBase32(c) {
+			continue
+		}
+		cand, ok := confusables[c]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: invalid character %q at offset %d", ErrInvalidFormat, c, offs[i])
+		}
+		to := cand[0]
+		if i == len(sig)-1 {
+			// the last character only accepts some letters
+			for j := 0; j < len(cand); j++ {
+				if isLastBase32(cand[j]) {
+					to = cand[j]
+					break
+				}
+			}
+		}
+		sig[i] = to
+		corr = append(corr, Correction{Kind: CorrectionConfusable, Offset: offs[i], From: string(c), To: string(to)})
+	}
+
+	// compare hyphens with the canonical layout, expressed as the number of
+	// significant characters preceding each of them
+	var want []int
+	if pfxLn > 0 {
+		want = append(want, pfxLn)
+	}
+	for _, h := range []int{6, 10, 14, 18} {
+		want = append(want, pfxLn+h)
+	}
+	found := make(map[int]bool)
+	for _, h := range hyphs {
+		if !found[h.count] && containsInt(want, h.count) {
+			found[h.count] = true
+			continue
+		}
+		corr = append(corr, Correction{Kind: CorrectionSeparator, Offset: h.off, From: "-"})
+	}
+	for _, c := range want {
+		if !found[c] {
+			corr = append(corr, Correction{Kind: CorrectionSeparator, Offset: offs[c], To: "-"})
+		}
+	}
+	sort.SliceStable(corr, func(i, j int) bool { return corr[i].Offset < corr[j].Offset })
+
+	// build the canonical form
+	var b strings.Builder
+	for i, c := range sig {
+		if containsInt(want, i) {
+			b.WriteByte('-')
+		}
+		b.WriteByte(c)
+	}
+	x, err := Parse(b.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return x, corr, nil
+}
+
+// parseLenientURN parses the URN t, which is s without surrounding
+// whitespace, reporting the removed whitespace as corrections
+func parseLenientURN(s, t string) (*XUID, []Correction, error) {
+	x, err := ParseURN(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	var corr []Correction
+	start := strings.Index(s, t)
+	for i, r := range s {
+		if i < start || i >= start+len(t) {
+			corr = append(corr, Correction{Kind: CorrectionWhitespace, Offset: i, From: string(r)})
+		}
+	}
+	return x, corr, nil
+}
+
+// containsInt reports whether v is in s
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package xuid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseLenient(t *testing.T) {
+	const want = "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		corr  []Correction
+	}{
+		{
+			name:  "Canonical",
+			input: want,
+			want:  want,
+		},
+		{
+			name:  "Uppercase",
+			input: "SHELL-H4NU2N-ZU3F-DMNN-KGUV-6F643NEI",
+			want:  want,
+		},
+		{
+			name:  "Whitespace",
+			input: " shell-h4nu2n-zu3f dmnn-kguv-6f643nei\n",
+			want:  want,
+			corr: []Correction{
+				{Kind: CorrectionWhitespace, Offset: 0, From: " "},
+				{Kind: CorrectionWhitespace, Offset: 18, From: " "},
+				{Kind: CorrectionSeparator, Offset: 19, To: "-"},
+				{Kind: CorrectionWhitespace, Offset: 37, From: "\n"},
+			},
+		},
+		{
+			name:  "No hyphens",
+			input: "shellh4nu2nzu3fdmnnkguv6f643nei",
+			want:  want,
+			corr: []Correction{
+				{Kind: CorrectionSeparator, Offset: 5, To: "-"},
+				{Kind: CorrectionSeparator, Offset: 11, To: "-"},
+				{Kind: CorrectionSeparator, Offset: 15, To: "-"},
+				{Kind: CorrectionSeparator, Offset: 19, To: "-"},
+				{Kind: CorrectionSeparator, Offset: 23, To: "-"},
+			},
+		},
+		{
+			name:  "Misplaced hyphen",
+			input: "shell-h4nu2n-zu3f-dmnn-kg-uv6f643nei",
+			want:  want,
+			corr: []Correction{
+				{Kind: CorrectionSeparator, Offset: 25, From: "-"},
+				{Kind: CorrectionSeparator, Offset: 28, To: "-"},
+			},
+		},
+		{
+			name:  "Confusables",
+			input: "null-aaaaaa-0000-8888-aaaa-aaaaaaa1",
+			want:  "null-aaaaaa-oooo-bbbb-aaaa-aaaaaaai",
+			corr: []Correction{
+				{Kind: CorrectionConfusable, Offset: 12, From: "0", To: "o"},
+				{Kind: CorrectionConfusable, Offset: 13, From: "0", To: "o"},
+				{Kind: CorrectionConfusable, Offset: 14, From: "0", To: "o"},
+				{Kind: CorrectionConfusable, Offset: 15, From: "0", To: "o"},
+				{Kind: CorrectionConfusable, Offset: 17, From: "8", To: "b"},
+				{Kind: CorrectionConfusable, Offset: 18, From: "8", To: "b"},
+				{Kind: CorrectionConfusable, Offset: 19, From: "8", To: "b"},
+				{Kind: CorrectionConfusable, Offset: 20, From: "8", To: "b"},
+				{Kind: CorrectionConfusable, Offset: 34, From: "1", To: "i"},
+			},
+		},
+		{
+			name:  "Confusable one",
+			input: "null-1aaaaa-aaaa-aaaa-aaaa-aaaaaaaa",
+			want:  "null-laaaaa-aaaa-aaaa-aaaa-aaaaaaaa",
+			corr:  []Correction{{Kind: CorrectionConfusable, Offset: 5, From: "1", To: "l"}},
+		},
+		{
+			name:  "UUID",
+			input: " 3f1b4d37-34d9-46c6-b546-a57c5f736d22",
+			want:  "h4nu2n-zu3f-dmnn-kguv-6f643nei",
+			corr:  []Correction{{Kind: CorrectionWhitespace, Offset: 0, From: " "}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, corr, err := ParseLenient(tt.input)
+			if err != nil {
+				t.Fatalf("ParseLenient(%q) error = %v", tt.input, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseLenient(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+			if !reflect.DeepEqual(corr, tt.corr) {
+				t.Errorf("ParseLenient(%q) corrections = %v, want %v", tt.input, corr, tt.corr)
+			}
+		})
+	}
+}
+
+func TestParseLenientMatchesParse(t *testing.T) {
+	for _, s := range []string{
+		"shell-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"SHELL-H4NU2N-ZU3F-DMNN-KGUV-6F643NEI",
+		"Shell-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"H4NU2N-ZU3F-DMNN-KGUV-6F643NEI",
+	} {
+		want, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", s, err)
+		}
+		got, corr, err := ParseLenient(s)
+		if err != nil {
+			t.Fatalf("ParseLenient(%q) error = %v", s, err)
+		}
+		if !got.Equals(*want) || corr != nil {
+			t.Errorf("ParseLenient(%q) = %+v, %v, want %+v", s, got, corr, want)
+		}
+	}
+}
+
+func TestParseLenientErrors(t *testing.T) {
+	if _, _, err := ParseLenient("shell-h4nu2n-zu3f-dmnn-kguv-6f643ne!"); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("ParseLenient() with invalid character error = %v, want %v", err, ErrInvalidFormat)
+	}
+	if _, _, err := ParseLenient("shell-h4nu2n"); err == nil {
+		t.Errorf("ParseLenient() with short input did not return an error")
+	}
+
+	c := Correction{Kind: CorrectionConfusable, Offset: 3, From: "0", To: "o"}
+	if c.String() != `replaced "0" with "o" at offset 3` || c.Kind.String() != "confusable" {
+		t.Errorf("Correction.String() = %q", c.String())
+	}
+}
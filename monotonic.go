@@ -0,0 +1,51 @@
+package xuid
+
+import "github.com/google/uuid"
+
+// MonotonicGenerator generates version 7 XUIDs that are strictly increasing
+// in binary order, even when many IDs are created within one millisecond or
+// when the clock steps backwards.
+//
+// The 12 bits following the timestamp hold a sequence number, initialized
+// randomly in its lower half on each new millisecond and incremented for
+// each ID created within the same millisecond. When the sequence overflows,
+// or when the clock goes back in time, the generator keeps using the last
+// timestamp it emitted (moving it one millisecond ahead on overflow) until
+// the clock catches up. The remaining 62 bits are random.
+//
+// A MonotonicGenerator is safe for concurrent use. Ordering is guaranteed
+// for IDs coming from the same generator only, or across restarts when the
+// generator is configured with WithStateStore.
+type MonotonicGenerator struct {
+	cfg   generatorConfig
+	clock clockSeq
+}
+
+// NewMonotonicGenerator returns a new MonotonicGenerator, using the clock set
+// with WithClock, the entropy source set with WithRand (crypto/rand by
+// default) and the state store set with WithStateStore, if any.
+func NewMonotonicGenerator(opts ...GeneratorOption) *MonotonicGenerator {
+	g := &MonotonicGenerator{cfg: newGeneratorConfig(opts)}
+	g.clock = clockSeq{cfg: &g.cfg, maxSeq: 0xfff}
+	return g
+}
+
+// New implements Generator
+func (g *MonotonicGenerator) New(prefix string) (XUID, error) {
+	var u uuid.UUID
+	if err := g.cfg.read(u[:]); err != nil {
+		return XUID{}, err
+	}
+
+	// on a new millisecond, start the sequence at a random point leaving
+	// room for at least 2048 more IDs
+	ms, seq, err := g.clock.next(uint64(u[6]&0x07)<<8 | uint64(u[7]))
+	if err != nil {
+		return XUID{}, err
+	}
+
+	setV7(&u, ms)
+	u[6] = 0x70 | byte(seq>>8)
+	u[7] = byte(seq)
+	return XUID{Prefix: prefix, UUID: u}, nil
+}
@@ -0,0 +1,70 @@
+package xuid
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMonotonicGeneratorClockRegression(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	g := NewMonotonicGenerator(WithClock(func() time.Time { return now }))
+
+	var last XUID
+	for i := 0; i < 10000; i++ {
+		switch i {
+		case 3000:
+			now = now.Add(-time.Second) // clock steps backwards
+		case 6000:
+			now = now.Add(2 * time.Second)
+		}
+		x, err := g.New("evt")
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if x.UUID.Version() != 7 {
+			t.Fatalf("New() version = %d, want 7", x.UUID.Version())
+		}
+		if i > 0 && bytes.Compare(x.UUID[:], last.UUID[:]) <= 0 {
+			t.Fatalf("New() #%d %s is not greater than %s", i, x, last)
+		}
+		last = x
+	}
+}
+
+func TestMonotonicGeneratorConcurrent(t *testing.T) {
+	const workers, count = 16, 2000
+	g := NewMonotonicGenerator()
+
+	res := make([][]XUID, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				x, err := g.New("evt")
+				if err != nil {
+					t.Errorf("New() error = %v", err)
+					return
+				}
+				res[w] = append(res[w], x)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[XUID]bool, workers*count)
+	for w, ids := range res {
+		for i, x := range ids {
+			if i > 0 && bytes.Compare(x.UUID[:], ids[i-1].UUID[:]) <= 0 {
+				t.Fatalf("worker %d: ID #%d %s is not greater than %s", w, i, x, ids[i-1])
+			}
+			if seen[x] {
+				t.Fatalf("worker %d: ID %s was emitted twice", w, x)
+			}
+			seen[x] = true
+		}
+	}
+}
@@ -7,7 +7,7 @@ import "github.com/google/uuid"
 var refNs = uuid.MustParse("d16b6139-8989-467f-a240-441df6734f45")
 
 // New creates a new random XUID with the given prefix.
-// It's a shorthand for Must(NewRandom(prefix)) and will panic if the random
+// It's a shorthand for Must(NewRandom(prefix)) and will panic if the
 // generator fails for some reason.
 //
 // This is the most common method for creating new XUIDs.
@@ -35,14 +35,15 @@ func FromUUID(u uuid.UUID, prefix string) (*XUID, error) {
 }
 
 // NewRandom generates a new random XUID with the given prefix.
-// It uses the underlying uuid.NewRandom() function to generate
-// a version 4 (random) UUID and assigns the provided prefix.
+// It delegates to the package default generator, which unless replaced with
+// SetDefaultGenerator uses uuid.NewRandom() to generate a version 4 (random)
+// UUID and assigns the provided prefix.
 func NewRandom(prefix string) (*XUID, error) {
-	u, err := uuid.NewRandom()
+	x, err := DefaultGenerator().New(prefix)
 	if err != nil {
 		return nil, err
 	}
-	return FromUUID(u, prefix)
+	return &x, nil
 }
 
 // FromKey generates a deterministic XUID based on the provided key string.
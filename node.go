@@ -0,0 +1,163 @@
+package xuid
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// nodePayloadBits is the number of bits available after the timestamp in a
+// version 8 UUID, excluding the version and variant bits
+const nodePayloadBits = 74
+
+// NodeLayout describes how the bits following the timestamp of a node-aware
+// version 8 UUID are used. The layout is, from the most significant bits:
+//
+//	48 bits  Unix timestamp in milliseconds
+//	 4 bits  version (8)
+//	NodeBits node ID
+//	SeqBits  sequence number within the millisecond
+//	 ...     random bits, with the 2 variant bits in the middle
+//
+// NodeBits and SeqBits must each be between 1 and 32 and together leave at
+// least 10 random bits. The same layout must be used to generate and inspect
+// IDs.
+type NodeLayout struct {
+	NodeBits int
+	SeqBits  int
+}
+
+// DefaultNodeLayout allows 1024 nodes each generating up to 4096 IDs per
+// millisecond, leaving 52 random bits.
+var DefaultNodeLayout = NodeLayout{NodeBits: 10, SeqBits: 12}
+
+// NodeInfo holds the fields extracted from a node-aware XUID by
+// NodeLayout.Inspect.
+type NodeInfo struct {
+	Time time.Time
+	Node uint64
+	Seq  uint64
+}
+
+// validate checks the layout bit budget
+func (l NodeLayout) validate() error {
+	if l.NodeBits < 1 || l.NodeBits > 32 || l.SeqBits < 1 || l.SeqBits > 32 || l.NodeBits+l.SeqBits > nodePayloadBits-10 {
+		return fmt.Errorf("%w: %d node bits and %d sequence bits", ErrBadLayout, l.NodeBits, l.SeqBits)
+	}
+	return nil
+}
+
+// Inspect extracts the timestamp, node ID and sequence number of a XUID
+// generated by a NodeGenerator using layout l. It returns ErrNotNodeID if x
+// is not a version 8 UUID.
+func (l NodeLayout) Inspect(x XUID) (NodeInfo, error) {
+	if err := l.validate(); err != nil {
+		return NodeInfo{}, err
+	}
+	if x.UUID.Version() != 8 || x.UUID.Variant() != uuid.RFC4122 {
+		return NodeInfo{}, ErrNotNodeID
+	}
+
+	var ms int64
+	for _, b := range x.UUID[:6] {
+		ms = ms<<8 | int64(b)
+	}
+	return NodeInfo{
+		Time: time.UnixMilli(ms),
+		Node: getPayload(&x.UUID, 0, l.NodeBits),
+		Seq:  getPayload(&x.UUID, l.NodeBits, l.SeqBits),
+	}, nil
+}
+
+// NodeGenerator generates snowflake style version 8 XUIDs encoding the ID of
+// the node that created them, see NodeLayout for the bit layout.
+//
+// IDs from a given generator are strictly increasing: the sequence number is
+// reset on each new millisecond and incremented for each ID created within
+// the same millisecond. On sequence overflow or when the clock goes back, the
+// generator moves ahead of the clock like MonotonicGenerator does, and with
+// WithStateStore it keeps doing so across restarts. IDs from different nodes
+// never collide as long as each node ID is used by a single generator at a
+// time.
+//
+// A NodeGenerator is safe for concurrent use.
+type NodeGenerator struct {
+	cfg    generatorConfig
+	layout NodeLayout
+	node   uint64
+	clock  clockSeq
+}
+
+// NewNodeGenerator returns a generator for the given node ID using layout,
+// the clock set with WithClock, the entropy source set with WithRand and the
+// state store set with WithStateStore, if any. It fails if the layout is
+// invalid or the node ID does not fit in it.
+func NewNodeGenerator(node uint64, layout NodeLayout, opts ...GeneratorOption) (*NodeGenerator, error) {
+	if err := layout.validate(); err != nil {
+		return nil, err
+	}
+	if node >= 1<<layout.NodeBits {
+		return nil, fmt.Errorf("%w: node %d does not fit in %d bits", ErrBadLayout, node, layout.NodeBits)
+	}
+	g := &NodeGenerator{cfg: newGeneratorConfig(opts), layout: layout, node: node}
+	g.clock = clockSeq{cfg: &g.cfg, maxSeq: 1<<layout.SeqBits - 1}
+	return g, nil
+}
+
+// Node returns the node ID of the generator.
+func (g *NodeGenerator) Node() uint64 {
+	return g.node
+}
+
+// New implements Generator
+func (g *NodeGenerator) New(prefix string) (XUID, error) {
+	var u uuid.UUID
+	if err := g.cfg.read(u[:]); err != nil {
+		return XUID{}, err
+	}
+
+	ms, seq, err := g.clock.next(0)
+	if err != nil {
+		return XUID{}, err
+	}
+
+	setV7(&u, ms)
+	u[6] = 0x80 | (u[6] & 0x0f) // Version 8
+	putPayload(&u, 0, g.layout.NodeBits, g.node)
+	putPayload(&u, g.layout.NodeBits, g.layout.SeqBits, seq)
+	return XUID{Prefix: prefix, UUID: u}, nil
+}
+
+// payloadBit returns the position in u, counted from the most significant
+// bit, of bit i of the payload following the timestamp, skipping the version
+// and variant bits
+func payloadBit(i int) int {
+	if i < 12 {
+		return 52 + i
+	}
+	return 54 + i
+}
+
+// putPayload stores the width lower bits of v at payload offset off
+func putPayload(u *uuid.UUID, off, width int, v uint64) {
+	for i := 0; i < width; i++ {
+		p := payloadBit(off + i)
+		mask := byte(0x80) >> (p % 8)
+		if v>>(width-1-i)&1 != 0 {
+			u[p/8] |= mask
+		} else {
+			u[p/8] &^= mask
+		}
+	}
+}
+
+// getPayload returns the width bits found at payload offset off
+func getPayload(u *uuid.UUID, off, width int) uint64 {
+	var v uint64
+	for i := 0; i < width; i++ {
+		p := payloadBit(off + i)
+		v = v<<1 | uint64(u[p/8]>>(7-p%8)&1)
+	}
+	return v
+}
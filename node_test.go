@@ -0,0 +1,85 @@
+package xuid
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNodeGeneratorInspect(t *testing.T) {
+	now := time.UnixMilli(1700000000000)
+	layouts := []NodeLayout{DefaultNodeLayout, {NodeBits: 1, SeqBits: 1}, {NodeBits: 32, SeqBits: 32}, {NodeBits: 16, SeqBits: 4}}
+
+	for _, l := range layouts {
+		node := uint64(1)<<l.NodeBits - 1
+		g, err := NewNodeGenerator(node, l, WithClock(func() time.Time { return now }))
+		if err != nil {
+			t.Fatalf("NewNodeGenerator(%+v) error = %v", l, err)
+		}
+
+		for i := 0; i < 3; i++ {
+			x, err := g.New("job")
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if x.UUID.Version() != 8 {
+				t.Errorf("New() version = %d, want 8", x.UUID.Version())
+			}
+			info, err := l.Inspect(x)
+			if err != nil {
+				t.Fatalf("Inspect() error = %v", err)
+			}
+			if info.Node != node {
+				t.Errorf("Inspect(%+v) node = %d, want %d", l, info.Node, node)
+			}
+			// layouts with a single sequence bit borrow the next millisecond
+			if l.SeqBits > 1 && (info.Seq != uint64(i) || !info.Time.Equal(now)) {
+				t.Errorf("Inspect(%+v) = %+v, want seq %d at %s", l, info, i, now)
+			}
+		}
+	}
+}
+
+func TestNodeGeneratorCollisions(t *testing.T) {
+	// nodes sharing the same clock and entropy must still produce distinct IDs
+	const nodes, count = 8, 5000
+	now := time.UnixMilli(1700000000000)
+	clock := func() time.Time { return now }
+
+	seen := make(map[XUID]bool, nodes*count)
+	for n := uint64(0); n < nodes; n++ {
+		g, err := NewNodeGenerator(n, DefaultNodeLayout, WithClock(clock), WithRand(rand.New(rand.NewSource(1))))
+		if err != nil {
+			t.Fatalf("NewNodeGenerator() error = %v", err)
+		}
+		var last XUID
+		for i := 0; i < count; i++ {
+			x, err := g.New("job")
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if seen[x] {
+				t.Fatalf("node %d emitted %s which was already emitted", n, x)
+			}
+			seen[x] = true
+			if i > 0 && bytes.Compare(x.UUID[:], last.UUID[:]) <= 0 {
+				t.Fatalf("node %d: %s is not greater than %s", n, x, last)
+			}
+			last = x
+		}
+	}
+}
+
+func TestNodeLayoutErrors(t *testing.T) {
+	if _, err := NewNodeGenerator(0, NodeLayout{NodeBits: 40, SeqBits: 30}); !errors.Is(err, ErrBadLayout) {
+		t.Errorf("NewNodeGenerator() with too many bits error = %v, want %v", err, ErrBadLayout)
+	}
+	if _, err := NewNodeGenerator(1024, DefaultNodeLayout); !errors.Is(err, ErrBadLayout) {
+		t.Errorf("NewNodeGenerator() with node out of range error = %v, want %v", err, ErrBadLayout)
+	}
+	if _, err := DefaultNodeLayout.Inspect(*New("test")); !errors.Is(err, ErrNotNodeID) {
+		t.Errorf("Inspect() of a v4 XUID error = %v, want %v", err, ErrNotNodeID)
+	}
+}
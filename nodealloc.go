@@ -0,0 +1,220 @@
+package xuid
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// NodeAllocator leases node IDs to generators such as NodeGenerator, so
+// that concurrent workers never share a node ID.
+type NodeAllocator interface {
+	// Acquire leases a free node ID. It returns ErrNoFreeNode if all node
+	// IDs are currently leased.
+	Acquire() (NodeLease, error)
+}
+
+// NodeLease is a node ID leased from a NodeAllocator. The lease must be
+// renewed regularly until it is released, otherwise it expires and the node
+// ID may be handed to another worker.
+type NodeLease interface {
+	// Node returns the leased node ID.
+	Node() uint64
+
+	// Renew extends the lease. It returns ErrLeaseLost if the lease expired
+	// and was taken over, in which case the node ID must stop being used.
+	Renew() error
+
+	// Release gives the node ID back to the allocator.
+	Release() error
+}
+
+// MaxNodes returns the number of distinct node IDs allowed by the layout.
+func (l NodeLayout) MaxNodes() uint64 {
+	return 1 << l.NodeBits
+}
+
+// DirAllocator is a NodeAllocator keeping one lock file per leased node ID in
+// a shared directory. It works for processes on a single host as well as on
+// several hosts sharing the directory over NFS, since it only relies on
+// exclusive file creation and renames.
+//
+// A lease expires when its lock file was not renewed for the allocator TTL,
+// so leases should be renewed several times per TTL period.
+type DirAllocator struct {
+	dir   string
+	nodes uint64
+	ttl   time.Duration
+}
+
+// NewDirAllocator returns an allocator leasing node IDs from 0 to nodes-1
+// using lock files in dir, which must exist. Leases expire after ttl without
+// renewal.
+func NewDirAllocator(dir string, nodes uint64, ttl time.Duration) *DirAllocator {
+	return &DirAllocator{dir: dir, nodes: nodes, ttl: ttl}
+}
+
+// Acquire implements NodeAllocator, returning the lowest free node ID.
+func (a *DirAllocator) Acquire() (NodeLease, error) {
+	var tok [16]byte
+	if _, err := rand.Read(tok[:]); err != nil {
+		return nil, err
+	}
+	token := []byte(hex.EncodeToString(tok[:]) + "\n")
+
+	for n := uint64(0); n < a.nodes; n++ {
+		l := &dirLease{path: a.path(n), node: n, token: token}
+		ok, err := l.create()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if ok, err = a.takeover(l); err != nil {
+				return nil, err
+			}
+		}
+		if ok {
+			return l, nil
+		}
+	}
+	return nil, ErrNoFreeNode
+}
+
+// path returns the lock file of node n
+func (a *DirAllocator) path(n uint64) string {
+	return filepath.Join(a.dir, "node-"+strconv.FormatUint(n, 10)+".lease")
+}
+
+// takeover removes the lock file of l if it expired and tries to create it
+// again. Renaming the lock file first ensures only one allocator removes it.
+func (a *DirAllocator) takeover(l *dirLease) (bool, error) {
+	if !a.expired(l.path) {
+		return false, nil
+	}
+	tomb := l.path + "." + hex.EncodeToString(l.token[:8]) + ".expired"
+	if err := os.Rename(l.path, tomb); err != nil {
+		if os.IsNotExist(err) {
+			// somebody else took over first, or the lease was released
+			return l.create()
+		}
+		return false, err
+	}
+	if !a.expired(tomb) {
+		// the lease was renewed right before the rename, give it back
+		giveBack(tomb, l.path)
+		return false, nil
+	}
+	os.Remove(tomb)
+	return l.create()
+}
+
+// giveBack restores the lock file moved aside to tomb. If another allocator
+// created a new lock file in the meantime, the tombstone is kept rather than
+// removed: the new lock file wins, and the owner of the tombstone gets
+// ErrLeaseLost on its next renewal.
+func giveBack(tomb, path string) {
+	if err := os.Link(tomb, path); err == nil {
+		os.Remove(tomb)
+	}
+}
+
+// expired reports whether the lock file at path was not renewed for the TTL
+func (a *DirAllocator) expired(path string) bool {
+	st, err := os.Stat(path)
+	return err == nil && time.Since(st.ModTime()) > a.ttl
+}
+
+// dirLease is a NodeLease held by a DirAllocator lock file
+type dirLease struct {
+	path  string
+	node  uint64
+	token []byte
+}
+
+// create creates the lock file, returning false if it already exists
+func (l *dirLease) create() (bool, error) {
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if _, err := f.Write(l.token); err != nil {
+		f.Close()
+		os.Remove(l.path)
+		return false, err
+	}
+	return true, f.Close()
+}
+
+// Node implements NodeLease
+func (l *dirLease) Node() uint64 {
+	return l.node
+}
+
+// renewRetryDelay is how long Renew waits before trying again when the lock
+// file is missing, since a concurrent takeover attempt may have moved it
+// aside for a moment before giving it back
+const renewRetryDelay = 10 * time.Millisecond
+
+// Renew implements NodeLease. The lock file is touched before its token is
+// checked: if the lease was taken over in between, the new owner's lease is
+// merely extended, and the check reports the loss. If the lock file is
+// missing, Renew tries again once after a short delay before reporting
+// ErrLeaseLost.
+func (l *dirLease) Renew() error {
+	err := l.renew()
+	if os.IsNotExist(err) {
+		time.Sleep(renewRetryDelay)
+		err = l.renew()
+	}
+	if os.IsNotExist(err) {
+		return ErrLeaseLost
+	}
+	return err
+}
+
+// renew touches the lock file and verifies it still holds the lease token
+func (l *dirLease) renew() error {
+	now := time.Now()
+	if err := os.Chtimes(l.path, now, now); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, l.token) {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release implements NodeLease. The lock file is first renamed to a private
+// tombstone and its token checked there, so that a lock file created by a
+// new owner after the lease expired is never removed.
+func (l *dirLease) Release() error {
+	tomb := l.path + "." + hex.EncodeToString(l.token[:8]) + ".released"
+	if err := os.Rename(l.path, tomb); err != nil {
+		if os.IsNotExist(err) {
+			// nothing left to release
+			return nil
+		}
+		return err
+	}
+	data, err := os.ReadFile(tomb)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, l.token) {
+		// the lease was taken over, give the lock file back to its owner
+		giveBack(tomb, l.path)
+		return nil
+	}
+	return os.Remove(tomb)
+}
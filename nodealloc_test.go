@@ -0,0 +1,134 @@
+package xuid
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirAllocator(t *testing.T) {
+	a := NewDirAllocator(t.TempDir(), 3, time.Minute)
+
+	var leases []NodeLease
+	for i := uint64(0); i < 3; i++ {
+		l, err := a.Acquire()
+		if err != nil {
+			t.Fatalf("Acquire() error = %v", err)
+		}
+		if l.Node() != i {
+			t.Errorf("Acquire() node = %d, want %d", l.Node(), i)
+		}
+		if err := l.Renew(); err != nil {
+			t.Errorf("Renew() error = %v", err)
+		}
+		leases = append(leases, l)
+	}
+
+	if _, err := a.Acquire(); !errors.Is(err, ErrNoFreeNode) {
+		t.Fatalf("Acquire() with all nodes leased error = %v, want %v", err, ErrNoFreeNode)
+	}
+
+	if err := leases[1].Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	l, err := a.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire() after Release() error = %v", err)
+	}
+	if l.Node() != 1 {
+		t.Errorf("Acquire() after Release() node = %d, want 1", l.Node())
+	}
+
+	// the lease feeds a node generator
+	g, err := NewNodeGenerator(l.Node(), DefaultNodeLayout)
+	if err != nil {
+		t.Fatalf("NewNodeGenerator() error = %v", err)
+	}
+	x, _ := g.New("job")
+	if info, err := DefaultNodeLayout.Inspect(x); err != nil || info.Node != 1 {
+		t.Errorf("Inspect() = %+v, %v, want node 1", info, err)
+	}
+}
+
+func TestDirAllocatorExpiry(t *testing.T) {
+	dir := t.TempDir()
+	a := NewDirAllocator(dir, 1, time.Minute)
+
+	old, err := a.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// simulate a worker that stopped renewing its lease
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(a.path(0), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := a.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire() of an expired lease error = %v", err)
+	}
+	if l.Node() != 0 {
+		t.Errorf("Acquire() node = %d, want 0", l.Node())
+	}
+
+	if err := old.Renew(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Renew() of a lost lease error = %v, want %v", err, ErrLeaseLost)
+	}
+	if err := old.Release(); err != nil {
+		t.Errorf("Release() of a lost lease error = %v", err)
+	}
+	// releasing the lost lease must not affect the new owner
+	if err := l.Renew(); err != nil {
+		t.Errorf("Renew() of the new lease error = %v", err)
+	}
+	if data, err := os.ReadFile(a.path(0)); err != nil || string(data) != string(l.(*dirLease).token) {
+		t.Errorf("lock file = %q, %v, want the new owner token", data, err)
+	}
+	if tombs, _ := filepath.Glob(filepath.Join(dir, "*.released")); len(tombs) != 0 {
+		t.Errorf("Release() left tombstones %q", tombs)
+	}
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(a.path(0)); !os.IsNotExist(err) {
+		t.Errorf("Release() left the lock file, stat error = %v", err)
+	}
+	if err := l.Renew(); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("Renew() of a released lease error = %v, want %v", err, ErrLeaseLost)
+	}
+}
+
+func TestDirAllocatorGiveBack(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "node-0.lease")
+	tomb := path + ".expired"
+
+	// the lock file is restored when nobody took the node in the meantime
+	if err := os.WriteFile(tomb, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	giveBack(tomb, path)
+	if data, err := os.ReadFile(path); err != nil || string(data) != "old\n" {
+		t.Errorf("giveBack() lock file = %q, %v, want %q", data, err, "old\n")
+	}
+	if _, err := os.Stat(tomb); !os.IsNotExist(err) {
+		t.Errorf("giveBack() kept the tombstone, stat error = %v", err)
+	}
+
+	// a lock file created in the meantime wins, and the tombstone is kept
+	if err := os.WriteFile(tomb, []byte("older\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	giveBack(tomb, path)
+	if data, err := os.ReadFile(path); err != nil || string(data) != "old\n" {
+		t.Errorf("giveBack() lock file = %q, %v, want %q", data, err, "old\n")
+	}
+	if _, err := os.Stat(tomb); err != nil {
+		t.Errorf("giveBack() removed the tombstone, stat error = %v", err)
+	}
+}
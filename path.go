@@ -0,0 +1,145 @@
+package xuid
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// pathEscape percent-encodes the characters of s that are not lowercase
+// letters, digits, hyphens or underscores, so that the result is safe as a
+// file name on any filesystem, including case-insensitive ones
+func pathEscape(s string) string {
+	const hex = "0123456789abcdef"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+	return b.String()
+}
+
+// Path returns a relative, slash separated path suitable to store an object
+// keyed by the XUID on a filesystem or in object storage, fanning out over
+// levels directories each named after the next width characters of the body.
+// For example, Path(2, 2) returns
+//
+//	shell/h4/nu/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei
+//
+// The first directory is the prefix, omitted for XUIDs without a prefix.
+// Paths are always lowercase, and characters of the prefix that are not safe
+// in file names are percent-encoded. Use filepath.FromSlash to obtain a path
+// for the local filesystem.
+//
+// width is clamped between 1 and 26, and levels between 0 and the number of
+// directories of that width fitting in the 26 characters of the body.
+func (x XUID) Path(levels, width int) string {
+	levels, width = clampPathLayout(levels, width)
+	s := x.String()
+	body := strings.ReplaceAll(s[len(s)-30:], "-", "")
+	parts := make([]string, 0, levels+2)
+	if len(s) > 30 {
+		parts = append(parts, pathEscape(s[:len(s)-31]))
+	}
+	for i := 0; i < levels; i++ {
+		parts = append(parts, body[i*width:(i+1)*width])
+	}
+	parts = append(parts, pathEscape(s))
+	return strings.Join(parts, "/")
+}
+
+// clampPathLayout clamps levels and width to the closest valid layout
+func clampPathLayout(levels, width int) (int, int) {
+	if width < 1 {
+		width = 1
+	} else if width > bodyLen {
+		width = bodyLen
+	}
+	if levels < 0 {
+		levels = 0
+	} else if levels > bodyLen/width {
+		levels = bodyLen / width
+	}
+	return levels, width
+}
+
+// validPathLayout reports whether levels directories of width characters
+// fit in the body of a XUID
+func validPathLayout(levels, width int) bool {
+	return levels >= 0 && width >= 1 && levels <= bodyLen/width
+}
+
+// parsePathName parses a file name produced by Path, optionally followed by
+// an extension starting with a dot
+func parsePathName(name string) (*XUID, bool) {
+	if p := strings.IndexByte(name, '.'); p >= 0 {
+		name = name[:p]
+	}
+	name = strings.ToLower(name)
+	s := unescapeNSS(name)
+	if !validNSS(name) || strings.IndexByte(name, '/') >= 0 {
+		return nil, false
+	}
+	x, err := Parse(s)
+	if err != nil || x.String() != s {
+		return nil, false
+	}
+	return x, true
+}
+
+// ParsePath parses a path produced by Path with the same levels and width.
+// The path may contain leading directories, use either slashes or the
+// separator of the local filesystem, and its file name may be followed by an
+// extension. Matching is case-insensitive. It returns ErrBadPath if the
+// directories do not match the XUID, or if levels and width are outside of
+// the range Path would clamp them to.
+func ParsePath(p string, levels, width int) (*XUID, error) {
+	if !validPathLayout(levels, width) {
+		return nil, fmt.Errorf("%w: invalid layout %d*%d", ErrBadPath, levels, width)
+	}
+	p = filepath.ToSlash(p)
+	x, ok := parsePathName(path.Base(p))
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not a XUID", ErrBadPath, path.Base(p))
+	}
+
+	want := strings.Split(x.Path(levels, width), "/")
+	got := strings.Split(p, "/")
+	if len(got) < len(want) {
+		return nil, fmt.Errorf("%w: %q does not match the layout", ErrBadPath, p)
+	}
+	got = got[len(got)-len(want):]
+	for i := 0; i < len(want)-1; i++ {
+		if !strings.EqualFold(got[i], want[i]) {
+			return nil, fmt.Errorf("%w: %q does not match the layout", ErrBadPath, p)
+		}
+	}
+	return x, nil
+}
+
+// WalkFS walks the file tree rooted at root in fsys and calls fn for each
+// regular file whose name is a XUID in the form produced by Path, with an
+// optional extension. Other files are ignored, and the directory layout is
+// not checked. If fn returns an error, the walk stops and returns it.
+func WalkFS(fsys fs.FS, root string, fn func(path string, x XUID) error) error {
+	return fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if x, ok := parsePathName(d.Name()); ok {
+			return fn(p, *x)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,138 @@
+package xuid
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPath(t *testing.T) {
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	u := XUID{UUID: x.UUID}
+	tests := []struct {
+		name          string
+		x             XUID
+		levels, width int
+		want          string
+	}{
+		{"Flat", x, 0, 1, "shell/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{"Two levels", x, 2, 2, "shell/h4/nu/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{"Wide", x, 1, 8, "shell/h4nu2nzu/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{"No prefix", u, 2, 2, "h4/nu/h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{"Escaped prefix", XUID{Prefix: "a.b", UUID: x.UUID}, 1, 2, "a%2eb/h4/a%2eb-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.x.Path(tt.levels, tt.width)
+			if got != tt.want {
+				t.Errorf("Path() = %q, want %q", got, tt.want)
+			}
+			p, err := ParsePath(got, tt.levels, tt.width)
+			if err != nil {
+				t.Fatalf("ParsePath(%q) error = %v", got, err)
+			}
+			if !p.Equals(tt.x) {
+				t.Errorf("ParsePath(%q) = %s, want %s", got, p, tt.x)
+			}
+		})
+	}
+}
+
+func TestPathClamp(t *testing.T) {
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	tests := []struct {
+		levels, width int
+		want          string
+	}{
+		{-1, 2, "shell/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{1, 0, "shell/h/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{14, 2, x.Path(13, 2)},
+		{1, 30, "shell/h4nu2nzu3fdmnnkguv6f643nei/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{1 << 62, 4, x.Path(6, 4)},
+	}
+	for _, tt := range tests {
+		if got := x.Path(tt.levels, tt.width); got != tt.want {
+			t.Errorf("Path(%d, %d) = %q, want %q", tt.levels, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	want := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	valid := []string{
+		"data/shell/h4/nu/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"/srv/SHELL/H4/NU/SHELL-H4NU2N-ZU3F-DMNN-KGUV-6F643NEI",
+		"shell/h4/nu/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei.json.gz",
+	}
+	for _, p := range valid {
+		x, err := ParsePath(p, 2, 2)
+		if err != nil {
+			t.Errorf("ParsePath(%q) error = %v", p, err)
+			continue
+		}
+		if !x.Equals(want) {
+			t.Errorf("ParsePath(%q) = %s, want %s", p, x, want)
+		}
+	}
+
+	invalid := []string{
+		"shell/h4/nx/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"user/h4/nu/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"h4/nu/shell-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"shell/h4/nu/shell-h4nu2n-zu3f-dmnn-kguv-6f643ne",
+		"shell/h4/nu/readme.txt",
+	}
+	for _, p := range invalid {
+		if _, err := ParsePath(p, 2, 2); !errors.Is(err, ErrBadPath) {
+			t.Errorf("ParsePath(%q) error = %v, want %v", p, err, ErrBadPath)
+		}
+	}
+
+	for _, l := range [][2]int{{-1, 1}, {1, 0}, {14, 2}, {1, -1}} {
+		if _, err := ParsePath(valid[0], l[0], l[1]); !errors.Is(err, ErrBadPath) {
+			t.Errorf("ParsePath(%d, %d) error = %v, want %v", l[0], l[1], err, ErrBadPath)
+		}
+	}
+}
+
+func TestWalkFS(t *testing.T) {
+	ids := testIDs(t, "user", 20)
+	fsys := fstest.MapFS{
+		"root/README.md":    {},
+		"root/user/tmp.txt": {},
+	}
+	for _, x := range ids {
+		fsys["root/"+x.Path(2, 2)+".json"] = &fstest.MapFile{}
+	}
+
+	seen := NewSet()
+	err := WalkFS(fsys, "root", func(p string, x XUID) error {
+		if p != "root/"+x.Path(2, 2)+".json" {
+			t.Errorf("WalkFS() path = %q for %s", p, x)
+		}
+		seen.Add(x)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkFS() error = %v", err)
+	}
+	if seen.Len() != len(ids) {
+		t.Errorf("WalkFS() found %d IDs, want %d", seen.Len(), len(ids))
+	}
+	for _, x := range ids {
+		if !seen.Has(x) {
+			t.Errorf("WalkFS() did not find %s", x)
+		}
+	}
+
+	stop := errors.New("stop")
+	err = WalkFS(fsys, "root", func(string, XUID) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("WalkFS() error = %v, want %v", err, stop)
+	}
+	if err := WalkFS(fsys, "missing", func(string, XUID) error { return nil }); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("WalkFS() error = %v, want %v", err, fs.ErrNotExist)
+	}
+}
@@ -0,0 +1,72 @@
+package xuid
+
+// PseudonymRule is the policy a Pseudonymizer applies to the XUIDs of one
+// prefix.
+type PseudonymRule struct {
+	// Keep leaves the XUIDs unchanged, for instance for public identifiers
+	Keep bool
+	// Prefix replaces the prefix of the pseudonyms. The original prefix is
+	// kept when empty.
+	Prefix string
+}
+
+// Pseudonymizer consistently replaces XUIDs with pseudonyms that cannot be
+// linked back to the original IDs without the secret, typically to export
+// production data for analytics.
+//
+// Pseudonyms are version 8 UUIDs derived with HMAC-SHA256 from the secret,
+// the prefix and the UUID of the original XUID, so the same XUID always maps
+// to the same pseudonym for a given secret, and references between exported
+// records remain valid.
+type Pseudonymizer struct {
+	key   []byte
+	rules map[string]PseudonymRule
+}
+
+// NewPseudonymizer returns a Pseudonymizer using the given secret and rules,
+// keyed by prefix. XUIDs whose prefix has no rule are pseudonymized keeping
+// their prefix. It panics if the secret is empty.
+func NewPseudonymizer(secret []byte, rules map[string]PseudonymRule) *Pseudonymizer {
+	if len(secret) == 0 {
+		panic("xuid: pseudonymizer requires a secret")
+	}
+	p := &Pseudonymizer{key: append([]byte(nil), secret...), rules: make(map[string]PseudonymRule, len(rules))}
+	for pfx, r := range rules {
+		p.rules[pfx] = r
+	}
+	return p
+}
+
+// Map returns the pseudonym of x. XUIDs with a zero UUID are returned
+// unchanged, so that unset references remain unset.
+func (p *Pseudonymizer) Map(x XUID) XUID {
+	r := p.rules[x.Prefix]
+	if r.Keep || x.UUID == (XUID{}).UUID {
+		return x
+	}
+	pfx := x.Prefix
+	if r.Prefix != "" {
+		pfx = r.Prefix
+	}
+	return XUID{Prefix: pfx, UUID: keyedUUID(p.key, x)}
+}
+
+// Rewrite replaces in place every XUID reachable from v, which must be a
+// non-nil pointer, with its pseudonym. XUIDs are found with Walk, so XUID,
+// *XUID and []XUID fields are rewritten at any depth, and each XUID is
+// rewritten once even if it is reachable through several pointers.
+//
+// The prefixes required by xuid struct tags are checked on the whole value
+// before anything is rewritten: if one does not match, Rewrite returns the
+// *PrefixError and v is left unchanged.
+//
+// Unexported struct fields and map keys are left untouched.
+func (p *Pseudonymizer) Rewrite(v any) error {
+	if err := Walk(v, func(string, *XUID) error { return nil }); err != nil {
+		return err
+	}
+	return Walk(v, func(_ string, id *XUID) error {
+		*id = p.Map(*id)
+		return nil
+	})
+}
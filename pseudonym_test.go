@@ -0,0 +1,116 @@
+package xuid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPseudonymizerMap(t *testing.T) {
+	user := *MustParse("user-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	order := *MustParse("order-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba")
+	p := NewPseudonymizer([]byte("secret"), map[string]PseudonymRule{
+		"order": {Keep: true},
+		"user":  {Prefix: "anon"},
+	})
+
+	got := p.Map(user)
+	if got.Prefix != "anon" || got.UUID == user.UUID {
+		t.Errorf("Map(%s) = %s, want an anon pseudonym", user, got)
+	}
+	if got.UUID.Version() != 8 {
+		t.Errorf("Map(%s) version = %d, want 8", user, got.UUID.Version())
+	}
+	if again := p.Map(user); !again.Equals(got) {
+		t.Errorf("Map(%s) = %s then %s, want a stable pseudonym", user, got, again)
+	}
+	if other := NewPseudonymizer([]byte("other"), nil).Map(user); other.UUID == got.UUID {
+		t.Errorf("Map(%s) is the same for two secrets", user)
+	}
+	if got := p.Map(order); !got.Equals(order) {
+		t.Errorf("Map(%s) = %s, want it kept", order, got)
+	}
+
+	sess := *MustParse("sess-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca")
+	if got := p.Map(sess); got.Prefix != "sess" || got.UUID == sess.UUID {
+		t.Errorf("Map(%s) = %s, want a sess pseudonym", sess, got)
+	}
+	if zero := (XUID{Prefix: "user"}); !p.Map(zero).Equals(zero) {
+		t.Errorf("Map() of a zero XUID = %s, want it unchanged", p.Map(zero))
+	}
+}
+
+func TestPseudonymizerRewrite(t *testing.T) {
+	type item struct {
+		ID   XUID
+		Ref  *XUID
+		Tags []XUID
+	}
+	type export struct {
+		Owner  XUID
+		Items  []item
+		ByName map[string]XUID
+		Any    any
+		Self   *export
+		secret XUID
+	}
+
+	p := NewPseudonymizer([]byte("secret"), nil)
+	ids := testIDs(t, "user", 7)
+	ref := ids[1]
+	v := &export{
+		Owner: ids[0],
+		Items: []item{
+			{ID: ids[1], Ref: &ref, Tags: []XUID{ids[2], ids[3]}},
+			{ID: ids[4], Ref: &ref},
+		},
+		ByName: map[string]XUID{"a": ids[5]},
+		Any:    ids[6],
+		secret: ids[0],
+	}
+	v.Self = v
+
+	if err := p.Rewrite(v); err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	checks := []struct {
+		name      string
+		got, orig XUID
+	}{
+		{"Owner", v.Owner, ids[0]},
+		{"Items[0].ID", v.Items[0].ID, ids[1]},
+		{"Items[0].Ref", *v.Items[0].Ref, ids[1]},
+		{"Items[0].Tags[1]", v.Items[0].Tags[1], ids[3]},
+		{"Items[1].ID", v.Items[1].ID, ids[4]},
+		{"ByName[a]", v.ByName["a"], ids[5]},
+		{"Any", v.Any.(XUID), ids[6]},
+	}
+	for _, c := range checks {
+		if want := p.Map(c.orig); !c.got.Equals(want) {
+			t.Errorf("Rewrite() %s = %s, want %s", c.name, c.got, want)
+		}
+	}
+	if !v.secret.Equals(ids[0]) {
+		t.Errorf("Rewrite() changed an unexported field")
+	}
+
+	if err := p.Rewrite(*v); !errors.Is(err, ErrNotPointer) {
+		t.Errorf("Rewrite() of a struct error = %v, want %v", err, ErrNotPointer)
+	}
+}
+
+func TestPseudonymizerRewriteInvalid(t *testing.T) {
+	a := *MustParse("user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba")
+	b := *MustParse("usr-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca")
+	v := &struct {
+		A XUID `xuid:"prefix=user"`
+		B XUID `xuid:"prefix=user"`
+	}{A: a, B: b}
+
+	err := NewPseudonymizer([]byte("secret"), nil).Rewrite(v)
+	if !errors.Is(err, ErrBadPrefix) {
+		t.Fatalf("Rewrite() error = %v, want %v", err, ErrBadPrefix)
+	}
+	if !v.A.Equals(a) || !v.B.Equals(b) {
+		t.Errorf("Rewrite() changed the value despite failing: %+v", v)
+	}
+}
@@ -0,0 +1,174 @@
+package xuid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/google/uuid"
+)
+
+// RangeOrder is the ordering a Range is contiguous in.
+type RangeOrder int
+
+const (
+	// BinaryOrder orders XUIDs by the 16 bytes of their UUID, as databases
+	// do for BINARY(16) or native UUID columns.
+	BinaryOrder RangeOrder = iota
+
+	// TextOrder orders XUIDs by their string form, as databases do for text
+	// columns filled with Value. Because the base32 alphabet maps the digits
+	// 2-7 to the highest values while they sort before letters in ASCII, this
+	// ordering differs from BinaryOrder.
+	TextOrder
+)
+
+// maxUUID is the highest UUID, with all bits set
+var maxUUID = uuid.UUID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// Range is an inclusive range of XUIDs sharing a prefix, contiguous in the
+// given order.
+type Range struct {
+	Prefix     string
+	Start, End XUID
+	Order      RangeOrder
+}
+
+// SplitRange splits the keyspace of the given prefix into n contiguous ranges
+// of equal size in BinaryOrder, covering all 2^128 UUIDs. It returns nil if n
+// is not positive.
+func SplitRange(prefix string, n int) []Range {
+	return splitRange(prefix, n, BinaryOrder, func(u uuid.UUID) uuid.UUID { return u })
+}
+
+// SplitTextRange is like SplitRange but returns ranges contiguous in
+// TextOrder, for tables storing XUIDs in text columns.
+func SplitTextRange(prefix string, n int) []Range {
+	return splitRange(prefix, n, TextOrder, fromTextKey)
+}
+
+// splitRange splits the 128 bits space in n and converts each bound to a
+// UUID using conv
+func splitRange(prefix string, n int, order RangeOrder, conv func(uuid.UUID) uuid.UUID) []Range {
+	if n <= 0 {
+		return nil
+	}
+	res := make([]Range, n)
+	start := splitPoint(0, n)
+	for i := range res {
+		var end uuid.UUID
+		if i == n-1 {
+			end = maxUUID
+		} else {
+			end = splitPoint(i+1, n)
+			decrement(&end)
+		}
+		res[i] = Range{
+			Prefix: prefix,
+			Start:  XUID{Prefix: prefix, UUID: conv(start)},
+			End:    XUID{Prefix: prefix, UUID: conv(end)},
+			Order:  order,
+		}
+		start = splitPoint(i+1, n)
+	}
+	return res
+}
+
+// splitPoint returns floor(i * 2^128 / n) for 0 <= i < n
+func splitPoint(i, n int) uuid.UUID {
+	var u uuid.UUID
+	if i >= n {
+		return u
+	}
+	hi, r := bits.Div64(uint64(i), 0, uint64(n))
+	lo, _ := bits.Div64(r, 0, uint64(n))
+	binary.BigEndian.PutUint64(u[:8], hi)
+	binary.BigEndian.PutUint64(u[8:], lo)
+	return u
+}
+
+// decrement subtracts one from u seen as a 128 bits big endian integer
+func decrement(u *uuid.UUID) {
+	for i := 15; i >= 0; i-- {
+		u[i]--
+		if u[i] != 0xff {
+			return
+		}
+	}
+}
+
+// Contains reports whether x belongs to the range: it must have the range
+// prefix and be between the range bounds in the range order.
+func (r Range) Contains(x XUID) bool {
+	if x.Prefix != r.Prefix {
+		return false
+	}
+	if r.Order == TextOrder {
+		s := x.String()
+		return r.Start.String() <= s && s <= r.End.String()
+	}
+	return bytes.Compare(r.Start.UUID[:], x.UUID[:]) <= 0 && bytes.Compare(x.UUID[:], r.End.UUID[:]) <= 0
+}
+
+// Args returns the range bounds as query parameters for a BETWEEN clause,
+// as in:
+//
+//	db.Query("SELECT ... WHERE id BETWEEN ? AND ?", r.Args()...)
+//
+// Ranges in TextOrder return the values returned by XUID.Value, for text
+// columns. Ranges in BinaryOrder return the 16 bytes of the UUIDs, for binary
+// columns.
+func (r Range) Args() []any {
+	if r.Order == TextOrder {
+		start, _ := r.Start.Value()
+		end, _ := r.End.Value()
+		return []any{start, end}
+	}
+	return []any{r.Start.UUID[:], r.End.UUID[:]}
+}
+
+// textKey maps u to a 128 bits integer whose ordering is the ordering of the
+// string form of u. Each base32 digit value is rotated so that the digits
+// 2-7 come before the letters, the last digit only holding 3 bits.
+func textKey(u uuid.UUID) uuid.UUID {
+	return rotateDigits(u, 6, 1)
+}
+
+// fromTextKey is the inverse of textKey
+func fromTextKey(k uuid.UUID) uuid.UUID {
+	return rotateDigits(k, 26, 7)
+}
+
+// rotateDigits adds rot to each of the 25 first 5 bits digits of u and
+// rotLast to the final 3 bits digit, modulo their size
+func rotateDigits(u uuid.UUID, rot, rotLast uint64) uuid.UUID {
+	hi := binary.BigEndian.Uint64(u[:8])
+	lo := binary.BigEndian.Uint64(u[8:])
+	var rhi, rlo uint64
+	for pos := 0; pos < 128; pos += 5 {
+		width, r := 5, rot
+		if pos == 125 {
+			width, r = 3, rotLast
+		}
+		// extract width bits at pos, counted from the most significant bit
+		shift := uint(128 - pos - width)
+		mask := uint64(1)<<width - 1
+		var d uint64
+		if shift >= 64 {
+			d = hi >> (shift - 64) & mask
+		} else {
+			d = (lo>>shift | hi<<1<<(63-shift)) & mask
+		}
+		d = (d + r) & mask
+		if shift >= 64 {
+			rhi |= d << (shift - 64)
+		} else {
+			rlo |= d << shift
+			rhi |= d >> 1 >> (63 - shift)
+		}
+	}
+	var res uuid.UUID
+	binary.BigEndian.PutUint64(res[:8], rhi)
+	binary.BigEndian.PutUint64(res[8:], rlo)
+	return res
+}
@@ -0,0 +1,102 @@
+package xuid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSplitRange(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 16} {
+		ranges := SplitRange("user", n)
+		if len(ranges) != n {
+			t.Fatalf("SplitRange(%d) returned %d ranges", n, len(ranges))
+		}
+		if ranges[0].Start.UUID != uuid.Nil {
+			t.Errorf("SplitRange(%d) starts at %s", n, ranges[0].Start.ToUUID())
+		}
+		if ranges[n-1].End.UUID != maxUUID {
+			t.Errorf("SplitRange(%d) ends at %s", n, ranges[n-1].End.ToUUID())
+		}
+		for i := 1; i < n; i++ {
+			// ranges must be contiguous
+			next := ranges[i-1].End.UUID
+			for j := 15; j >= 0; j-- {
+				next[j]++
+				if next[j] != 0 {
+					break
+				}
+			}
+			if next != ranges[i].Start.UUID {
+				t.Errorf("SplitRange(%d) range %d ends at %s but range %d starts at %s", n, i-1, ranges[i-1].End.ToUUID(), i, ranges[i].Start.ToUUID())
+			}
+		}
+	}
+
+	ranges := SplitRange("user", 4)
+	if s := ranges[1].Start.ToUUID(); s != "40000000-0000-0000-0000-000000000000" {
+		t.Errorf("SplitRange(4)[1].Start = %s", s)
+	}
+	if args := ranges[1].Args(); !bytes.Equal(args[0].([]byte), ranges[1].Start.UUID[:]) {
+		t.Errorf("Args() = %v", args)
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	ids := testIDs(t, "user", 2000)
+
+	for _, split := range []func(string, int) []Range{SplitRange, SplitTextRange} {
+		ranges := split("user", 5)
+		for _, id := range ids {
+			found := 0
+			for _, r := range ranges {
+				if r.Contains(id) {
+					found++
+				}
+			}
+			if found != 1 {
+				t.Fatalf("%s is contained in %d ranges, want 1", id, found)
+			}
+		}
+		if ranges[0].Contains(XUID{Prefix: "doc", UUID: ranges[0].Start.UUID}) {
+			t.Errorf("Contains() accepted an ID with another prefix")
+		}
+	}
+}
+
+func TestSplitTextRange(t *testing.T) {
+	ranges := SplitTextRange("user", 4)
+
+	if s := ranges[0].Start.String(); s != "user-222222-2222-2222-2222-22222224" {
+		t.Errorf("SplitTextRange() first bound = %s", s)
+	}
+	if s := ranges[3].End.String(); s != "user-zzzzzz-zzzz-zzzz-zzzz-zzzzzzzy" {
+		t.Errorf("SplitTextRange() last bound = %s", s)
+	}
+	for i, r := range ranges {
+		args := r.Args()
+		if args[0] != r.Start.String() || args[1] != r.End.String() {
+			t.Errorf("Args() = %v", args)
+		}
+		if r.Start.String() > r.End.String() {
+			t.Errorf("range %d bounds are not in text order", i)
+		}
+		if i > 0 && ranges[i-1].End.String() >= r.Start.String() {
+			t.Errorf("range %d overlaps the previous one", i)
+		}
+	}
+
+	// text keys follow the ordering of the string form
+	ids := testIDs(t, "", 500)
+	for i := 1; i < len(ids); i++ {
+		a, b := ids[i-1], ids[i]
+		ka, kb := textKey(a.UUID), textKey(b.UUID)
+		if (a.String() < b.String()) != (bytes.Compare(ka[:], kb[:]) < 0) {
+			t.Fatalf("textKey() ordering of %s and %s differs from text ordering", a, b)
+		}
+		if fromTextKey(ka) != a.UUID {
+			t.Fatalf("fromTextKey(textKey(%s)) does not round trip", a)
+		}
+	}
+}
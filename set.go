@@ -0,0 +1,173 @@
+package xuid
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// setKey is the compact representation of a XUID in a Set, the prefix being
+// replaced by its index in the set prefix table. It holds no pointer, which
+// keeps large sets cheap for the garbage collector.
+type setKey struct {
+	uuid uuid.UUID
+	pfx  uint32
+}
+
+// Set is a set of XUIDs with a compact memory layout: prefixes are interned
+// and each member only takes 20 bytes plus the map overhead, instead of the
+// 32 bytes of a XUID holding a string header.
+//
+// The zero value is an empty set ready to use. A Set is not safe for
+// concurrent use.
+type Set struct {
+	prefixes []string
+	index    map[string]uint32
+	keys     map[setKey]struct{}
+}
+
+// NewSet returns a set containing the given XUIDs.
+func NewSet(ids ...XUID) *Set {
+	s := &Set{}
+	s.Add(ids...)
+	return s
+}
+
+// key returns the compact key of x, interning its prefix if add is set. It
+// returns false if the prefix is unknown and add is not set.
+func (s *Set) key(x XUID, add bool) (setKey, bool) {
+	pfx, ok := s.index[x.Prefix]
+	if !ok {
+		if !add {
+			return setKey{}, false
+		}
+		if s.index == nil {
+			s.index = make(map[string]uint32)
+		}
+		pfx = uint32(len(s.prefixes))
+		s.prefixes = append(s.prefixes, x.Prefix)
+		s.index[x.Prefix] = pfx
+	}
+	return setKey{uuid: x.UUID, pfx: pfx}, true
+}
+
+// xuid returns the XUID represented by k
+func (s *Set) xuid(k setKey) XUID {
+	return XUID{Prefix: s.prefixes[k.pfx], UUID: k.uuid}
+}
+
+// Add adds the given XUIDs to the set.
+func (s *Set) Add(ids ...XUID) {
+	if s.keys == nil {
+		s.keys = make(map[setKey]struct{}, len(ids))
+	}
+	for _, x := range ids {
+		k, _ := s.key(x, true)
+		s.keys[k] = struct{}{}
+	}
+}
+
+// Has reports whether x is in the set.
+func (s *Set) Has(x XUID) bool {
+	k, ok := s.key(x, false)
+	if !ok {
+		return false
+	}
+	_, ok = s.keys[k]
+	return ok
+}
+
+// Remove removes x from the set, if present.
+func (s *Set) Remove(x XUID) {
+	if k, ok := s.key(x, false); ok {
+		delete(s.keys, k)
+	}
+}
+
+// Len returns the number of XUIDs in the set.
+func (s *Set) Len() int {
+	return len(s.keys)
+}
+
+// Union returns a new set containing the XUIDs found in s or o.
+func (s *Set) Union(o *Set) *Set {
+	res := &Set{keys: make(map[setKey]struct{}, s.Len()+o.Len())}
+	for k := range s.keys {
+		res.Add(s.xuid(k))
+	}
+	for k := range o.keys {
+		res.Add(o.xuid(k))
+	}
+	return res
+}
+
+// Intersect returns a new set containing the XUIDs found in both s and o.
+func (s *Set) Intersect(o *Set) *Set {
+	if s.Len() > o.Len() {
+		s, o = o, s
+	}
+	res := &Set{}
+	for k := range s.keys {
+		if x := s.xuid(k); o.Has(x) {
+			res.Add(x)
+		}
+	}
+	return res
+}
+
+// Sorted returns the XUIDs of the set in the order defined by XUID.Compare.
+func (s *Set) Sorted() []XUID {
+	keys := make([]setKey, 0, len(s.keys))
+	for k := range s.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.pfx != b.pfx {
+			return s.prefixes[a.pfx] < s.prefixes[b.pfx]
+		}
+		return bytes.Compare(a.uuid[:], b.uuid[:]) < 0
+	})
+
+	res := make([]XUID, len(keys))
+	for i, k := range keys {
+		res[i] = s.xuid(k)
+	}
+	return res
+}
+
+// Range calls fn for each XUID of the set in the order defined by
+// XUID.Compare, stopping if fn returns false.
+func (s *Set) Range(fn func(x XUID) bool) {
+	for _, x := range s.Sorted() {
+		if !fn(x) {
+			return
+		}
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface for Set.
+// The set is encoded as a sorted array of XUID strings. It has a value
+// receiver so that sets stored by value, such as struct fields, are encoded
+// too.
+func (s Set) MarshalJSON() ([]byte, error) {
+	ids := s.Sorted()
+	if ids == nil {
+		ids = []XUID{}
+	}
+	return json.Marshal(ids)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for Set.
+// It expects an array of XUID strings, replacing the content of the set.
+func (s *Set) UnmarshalJSON(b []byte) error {
+	var ids []XUID
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return err
+	}
+	*s = Set{}
+	s.Add(ids...)
+	return nil
+}
@@ -0,0 +1,114 @@
+package xuid
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	a := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	b := *MustParse("null-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa")
+	c := *MustParse("user-h4nu2n-zu3f-dmnn-kguv-6f643nei") // same UUID as a
+
+	var s Set
+	if s.Has(a) || s.Len() != 0 {
+		t.Errorf("zero Set is not empty")
+	}
+	s.Add(a, b, a)
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+	if !s.Has(a) || !s.Has(b) || s.Has(c) {
+		t.Errorf("Has() returned wrong results")
+	}
+
+	s.Remove(a)
+	s.Remove(c)
+	if s.Has(a) || s.Len() != 1 {
+		t.Errorf("Remove() did not remove %s", a)
+	}
+
+	s1 := NewSet(a, b)
+	s2 := NewSet(b, c)
+	if u := s1.Union(s2); u.Len() != 3 || !u.Has(a) || !u.Has(b) || !u.Has(c) {
+		t.Errorf("Union() = %v", u.Sorted())
+	}
+	if i := s1.Intersect(s2); i.Len() != 1 || !i.Has(b) {
+		t.Errorf("Intersect() = %v", i.Sorted())
+	}
+}
+
+func TestSetSorted(t *testing.T) {
+	ids := append(testIDs(t, "user", 100), testIDs(t, "doc", 100)...)
+	s := NewSet(ids...)
+
+	sorted := s.Sorted()
+	if len(sorted) != 200 {
+		t.Fatalf("Sorted() returned %d IDs, want 200", len(sorted))
+	}
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i-1].Compare(sorted[i]) >= 0 {
+			t.Fatalf("Sorted() is not sorted at %d: %s >= %s", i, sorted[i-1], sorted[i])
+		}
+	}
+
+	n := 0
+	s.Range(func(x XUID) bool {
+		if !x.Equals(sorted[n]) {
+			t.Fatalf("Range() #%d = %s, want %s", n, x, sorted[n])
+		}
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Errorf("Range() did not stop, called %d times", n)
+	}
+}
+
+func TestSetJSON(t *testing.T) {
+	s := NewSet(*MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"), *MustParse("null-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa"))
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	want := `["null-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa","shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"]`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var decoded Set
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if decoded.Len() != 2 || decoded.Intersect(s).Len() != 2 {
+		t.Errorf("json.Unmarshal() = %v", decoded.Sorted())
+	}
+
+	if data, _ := json.Marshal(&Set{}); string(data) != "[]" {
+		t.Errorf("json.Marshal() of empty set = %s, want []", data)
+	}
+
+	// a set stored by value must be encoded as well
+	data, err = json.Marshal(struct{ S Set }{*s})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(data) != `{"S":`+want+`}` {
+		t.Errorf("json.Marshal() of a Set field = %s, want {\"S\":%s}", data, want)
+	}
+	var st struct{ S Set }
+	if err := json.Unmarshal(data, &st); err != nil || st.S.Len() != 2 {
+		t.Errorf("json.Unmarshal() of a Set field = %v, %v", st.S.Sorted(), err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := *MustParse("shell-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa")
+	b := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	c := *MustParse("user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa")
+
+	if a.Compare(b) != -1 || b.Compare(a) != 1 || a.Compare(a) != 0 || b.Compare(c) != -1 {
+		t.Errorf("Compare() returned wrong results")
+	}
+}
@@ -0,0 +1,177 @@
+package xuid
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// hash64 returns the 64 bits FNV-1a hash of the UUID bytes, preceded by the
+// prefix bytes and a zero byte if withPrefix is set
+func (x XUID) hash64(withPrefix bool) uint64 {
+	h := fnv.New64a()
+	if withPrefix {
+		h.Write([]byte(x.Prefix))
+		h.Write([]byte{0})
+	}
+	h.Write(x.UUID[:])
+	return h.Sum64()
+}
+
+// mix64 is the 64 bits finalizer of MurmurHash3, spreading the entropy of h
+// over all of its bits
+func mix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// Shard returns the shard, between 0 and n-1, the XUID belongs to.
+//
+// The shard is the 64 bits FNV-1a hash of the 16 bytes of the UUID, taken
+// modulo n. The prefix is not part of the hash, so the same UUID maps to the
+// same shard whatever its prefix. This definition is stable and can be
+// reimplemented in other languages or in SQL. It returns -1 if n is not
+// positive.
+func (x XUID) Shard(n int) int {
+	if n <= 0 {
+		return -1
+	}
+	return int(x.hash64(false) % uint64(n))
+}
+
+// ShardPrefixed is like Shard but includes the prefix in the hash: the FNV-1a
+// hash covers the prefix bytes, a zero byte, then the 16 bytes of the UUID.
+// It returns -1 if n is not positive.
+func (x XUID) ShardPrefixed(n int) int {
+	if n <= 0 {
+		return -1
+	}
+	return int(x.hash64(true) % uint64(n))
+}
+
+// JumpShard returns the shard, between 0 and n-1, the XUID belongs to using
+// the jump consistent hash algorithm of Lamping and Veach on the same hash
+// as Shard. When n grows to n+1, only 1/(n+1) of the IDs move to another
+// shard, all of them to the new one. It returns -1 if n is not positive.
+func (x XUID) JumpShard(n int) int {
+	key := x.hash64(false)
+	var b, j int64 = -1, 0
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// ringPoint is a virtual node position on a Ring
+type ringPoint struct {
+	hash uint64
+	node string
+}
+
+// Ring is a consistent hash ring mapping XUIDs to named nodes. Each node is
+// placed at several positions (virtual nodes) on the ring so that keys are
+// evenly spread, and adding or removing a node only moves the keys of that
+// node.
+//
+// Positions are the FNV-1a hash of the node name followed by "#" and the
+// virtual node index, keys are the FNV-1a hash used by Shard. Both are passed
+// through the MurmurHash3 64 bits finalizer to spread them evenly around the
+// ring. A Ring is safe for concurrent use.
+type Ring struct {
+	vnodes int
+
+	mu     sync.RWMutex
+	points []ringPoint
+}
+
+// NewRing returns a ring placing each node at vnodes positions and
+// containing the given nodes.
+func NewRing(vnodes int, nodes ...string) *Ring {
+	if vnodes < 1 {
+		vnodes = 1
+	}
+	r := &Ring{vnodes: vnodes}
+	for _, n := range nodes {
+		r.Add(n)
+	}
+	return r
+}
+
+// Add adds a node to the ring. Adding a node already present has no effect.
+func (r *Ring) Add(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.points {
+		if p.node == node {
+			return
+		}
+	}
+	for i := 0; i < r.vnodes; i++ {
+		h := fnv.New64a()
+		h.Write([]byte(node + "#" + strconv.Itoa(i)))
+		r.points = append(r.points, ringPoint{hash: mix64(h.Sum64()), node: node})
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		if r.points[i].hash != r.points[j].hash {
+			return r.points[i].hash < r.points[j].hash
+		}
+		return r.points[i].node < r.points[j].node
+	})
+}
+
+// Remove removes a node from the ring.
+func (r *Ring) Remove(node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	points := r.points[:0]
+	for _, p := range r.points {
+		if p.node != node {
+			points = append(points, p)
+		}
+	}
+	r.points = points
+}
+
+// Nodes returns the sorted list of nodes in the ring.
+func (r *Ring) Nodes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var res []string
+	seen := make(map[string]bool)
+	for _, p := range r.points {
+		if !seen[p.node] {
+			seen[p.node] = true
+			res = append(res, p.node)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Locate returns the node owning x, which is the node of the first position
+// following the hash of x on the ring. It returns an empty string if the ring
+// has no nodes.
+func (r *Ring) Locate(x XUID) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := mix64(x.hash64(false))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].node
+}
@@ -0,0 +1,116 @@
+package xuid
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// testIDs returns n reproducible random XUIDs
+func testIDs(t testing.TB, prefix string, n int) []XUID {
+	ids := make([]XUID, n)
+	g := NewRandomGenerator(WithRand(rand.New(rand.NewSource(1))))
+	if err := g.Fill(ids, prefix); err != nil {
+		t.Fatalf("Fill() error = %v", err)
+	}
+	return ids
+}
+
+func TestShard(t *testing.T) {
+	x := MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+
+	// stable values, must never change
+	if s := x.Shard(16); s != 3 {
+		t.Errorf("Shard(16) = %d, want 3", s)
+	}
+	if s := x.ShardPrefixed(16); s != 5 {
+		t.Errorf("ShardPrefixed(16) = %d, want 5", s)
+	}
+
+	// the prefix is ignored unless requested
+	y := *x
+	y.Prefix = "user"
+	if x.Shard(1000) != y.Shard(1000) {
+		t.Errorf("Shard() depends on the prefix")
+	}
+
+	counts := make([]int, 8)
+	for _, id := range testIDs(t, "test", 8000) {
+		counts[id.Shard(8)]++
+	}
+	for i, c := range counts {
+		if c < 800 || c > 1200 {
+			t.Errorf("Shard(8) assigned %d IDs out of 8000 to shard %d", c, i)
+		}
+	}
+}
+
+func TestJumpShard(t *testing.T) {
+	ids := testIDs(t, "test", 10000)
+
+	moved := 0
+	for _, id := range ids {
+		a, b := id.JumpShard(10), id.JumpShard(11)
+		if a < 0 || a >= 10 {
+			t.Fatalf("JumpShard(10) = %d", a)
+		}
+		if a != b {
+			if b != 10 {
+				t.Fatalf("JumpShard() moved %s from %d to %d instead of the new shard", id, a, b)
+			}
+			moved++
+		}
+	}
+	// about 1/11 of the keys should move
+	if moved < 700 || moved > 1100 {
+		t.Errorf("JumpShard() moved %d keys out of 10000 when adding a shard", moved)
+	}
+}
+
+func TestShardInvalid(t *testing.T) {
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	for _, n := range []int{0, -1, -100} {
+		if got := x.Shard(n); got != -1 {
+			t.Errorf("Shard(%d) = %d, want -1", n, got)
+		}
+		if got := x.ShardPrefixed(n); got != -1 {
+			t.Errorf("ShardPrefixed(%d) = %d, want -1", n, got)
+		}
+		if got := x.JumpShard(n); got != -1 {
+			t.Errorf("JumpShard(%d) = %d, want -1", n, got)
+		}
+	}
+}
+
+func TestRing(t *testing.T) {
+	r := NewRing(100, "a", "b", "c", "d")
+	ids := testIDs(t, "test", 10000)
+
+	before := make(map[XUID]string, len(ids))
+	counts := make(map[string]int)
+	for _, id := range ids {
+		n := r.Locate(id)
+		before[id] = n
+		counts[n]++
+	}
+	for _, n := range r.Nodes() {
+		if counts[n] < 1500 || counts[n] > 3500 {
+			t.Errorf("Ring assigned %d keys out of 10000 to node %s", counts[n], n)
+		}
+	}
+
+	// removing a node only moves the keys it owned
+	r.Remove("c")
+	for _, id := range ids {
+		n := r.Locate(id)
+		if n == "c" {
+			t.Fatalf("Locate() returned removed node")
+		}
+		if before[id] != "c" && n != before[id] {
+			t.Fatalf("Locate(%s) moved from %s to %s", id, before[id], n)
+		}
+	}
+
+	if got := NewRing(10).Locate(ids[0]); got != "" {
+		t.Errorf("Locate() on empty ring = %q, want empty", got)
+	}
+}
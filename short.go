@@ -0,0 +1,157 @@
+package xuid
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// bodyLen is the number of base32 characters in a XUID body
+const bodyLen = 26
+
+// Short returns an abbreviated form of the XUID, made of the prefix and the
+// first n characters of the body, such as user-h4nu2n for n = 6. Hyphens of
+// the canonical form are kept. n is clamped between 1 and 26, Short(26)
+// being equal to String().
+//
+// Abbreviations can be resolved back to the full XUID with an Index.
+func (x XUID) Short(n int) string {
+	if n < 1 {
+		n = 1
+	} else if n > bodyLen {
+		n = bodyLen
+	}
+	s := x.String()
+	start := 0
+	if x.Prefix != "" {
+		start = len(s) - 30
+	}
+	// add the hyphens located before the n-th character of the body
+	l := n
+	for _, h := range []int{6, 10, 14, 18} {
+		if h < n {
+			l++
+		}
+	}
+	return s[:start+l]
+}
+
+// AmbiguousError is returned by Index.Resolve when an abbreviation matches
+// several XUIDs.
+type AmbiguousError struct {
+	Abbrev     string
+	Candidates []XUID
+}
+
+func (e *AmbiguousError) Error() string {
+	s := make([]string, len(e.Candidates))
+	for i, x := range e.Candidates {
+		s[i] = x.String()
+	}
+	return fmt.Sprintf("xuid: ambiguous abbreviation %q matches %d XUIDs: %s", e.Abbrev, len(e.Candidates), strings.Join(s, ", "))
+}
+
+// indexEntry is a XUID stored in an Index, along with its lookup key
+type indexEntry struct {
+	key string
+	id  XUID
+}
+
+// Index resolves abbreviated XUIDs, as returned by Short, against a set of
+// known XUIDs, the same way git resolves short commit hashes.
+//
+// An Index is not safe for concurrent use.
+type Index struct {
+	entries []indexEntry
+	sorted  bool
+}
+
+// NewIndex returns an index of the given XUIDs.
+func NewIndex(ids ...XUID) *Index {
+	idx := &Index{}
+	idx.Add(ids...)
+	return idx
+}
+
+// abbrevKey returns the lookup key of a full or abbreviated XUID: the
+// lowercase prefix, a hyphen and the body without hyphens. A hyphen within
+// the first 6 characters separates the prefix, since the first group of the
+// body has 6 characters.
+func abbrevKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	var pfx string
+	if p := strings.IndexByte(s, '-'); p >= 0 && p <= 5 {
+		pfx, s = s[:p], s[p+1:]
+	}
+	return pfx + "-" + strings.ReplaceAll(s, "-", "")
+}
+
+// Add adds the given XUIDs to the index.
+func (idx *Index) Add(ids ...XUID) {
+	for _, x := range ids {
+		idx.entries = append(idx.entries, indexEntry{key: abbrevKey(x.String()), id: x})
+	}
+	idx.sorted = false
+}
+
+// sort sorts the entries by key and removes duplicates
+func (idx *Index) sort() {
+	if idx.sorted {
+		return
+	}
+	sort.Slice(idx.entries, func(i, j int) bool { return idx.entries[i].key < idx.entries[j].key })
+	res := idx.entries[:0]
+	for i, e := range idx.entries {
+		if i == 0 || e.key != idx.entries[i-1].key {
+			res = append(res, e)
+		}
+	}
+	idx.entries = res
+	idx.sorted = true
+}
+
+// Resolve returns the only XUID of the index starting with the given
+// abbreviation. Matching is case-insensitive and hyphens of the body are
+// optional. It returns ErrNoMatch if no XUID matches, or an *AmbiguousError
+// listing the candidates if several do.
+func (idx *Index) Resolve(abbrev string) (XUID, error) {
+	idx.sort()
+	key := abbrevKey(abbrev)
+	i := sort.Search(len(idx.entries), func(i int) bool { return idx.entries[i].key >= key })
+
+	var res []XUID
+	for ; i < len(idx.entries) && strings.HasPrefix(idx.entries[i].key, key); i++ {
+		res = append(res, idx.entries[i].id)
+	}
+	switch len(res) {
+	case 0:
+		return XUID{}, fmt.Errorf("%w: %q", ErrNoMatch, abbrev)
+	case 1:
+		return res[0], nil
+	default:
+		return XUID{}, &AmbiguousError{Abbrev: abbrev, Candidates: res}
+	}
+}
+
+// MinUnique returns the minimum number of body characters n such that
+// Short(n) is different for every XUID of the index.
+func (idx *Index) MinUnique() int {
+	idx.sort()
+	n := 1
+	for i := 1; i < len(idx.entries); i++ {
+		a, b := idx.entries[i-1].key, idx.entries[i].key
+		pa, pb := strings.IndexByte(a, '-'), strings.IndexByte(b, '-')
+		if a[:pa] != b[:pb] {
+			continue
+		}
+		// length of the common body prefix, plus one
+		l := 1
+		for a[pa+l] == b[pb+l] {
+			l++
+		}
+		if l > n {
+			n = l
+		}
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package xuid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestShort(t *testing.T) {
+	x := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "shell-h"},
+		{1, "shell-h"},
+		{6, "shell-h4nu2n"},
+		{7, "shell-h4nu2n-z"},
+		{10, "shell-h4nu2n-zu3f"},
+		{11, "shell-h4nu2n-zu3f-d"},
+		{26, "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{40, "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+	}
+	for _, tt := range tests {
+		if got := x.Short(tt.n); got != tt.want {
+			t.Errorf("Short(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+
+	if got := (XUID{UUID: x.UUID}).Short(8); got != "h4nu2n-zu" {
+		t.Errorf("Short(8) without prefix = %q, want %q", got, "h4nu2n-zu")
+	}
+}
+
+func TestIndex(t *testing.T) {
+	a := *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	b := *MustParse("shell-h4nu2n-zu3f-aaaa-aaaa-aaaaaaaa")
+	c := *MustParse("user-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	d := *MustParse("h4nu2n-aaaa-aaaa-aaaa-aaaaaaaa")
+	idx := NewIndex(a, b, c, d, a)
+
+	tests := []struct {
+		abbrev string
+		want   XUID
+	}{
+		{"shell-h4nu2n-zu3f-d", a},
+		{"SHELL-H4NU2N-ZU3F-D", a},
+		{"shell-h4nu2nzu3fd", a},
+		{"shell-h4nu2n-zu3f-a", b},
+		{"user-h", c},
+		{"h4nu2n", d},
+		{a.String(), a},
+	}
+	for _, tt := range tests {
+		got, err := idx.Resolve(tt.abbrev)
+		if err != nil {
+			t.Errorf("Resolve(%q) error = %v", tt.abbrev, err)
+			continue
+		}
+		if !got.Equals(tt.want) {
+			t.Errorf("Resolve(%q) = %s, want %s", tt.abbrev, got, tt.want)
+		}
+	}
+
+	_, err := idx.Resolve("shell-h4nu")
+	var amb *AmbiguousError
+	if !errors.As(err, &amb) || len(amb.Candidates) != 2 {
+		t.Errorf("Resolve() of ambiguous abbreviation error = %v", err)
+	}
+	if _, err := idx.Resolve("doc-h4"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("Resolve() of unknown abbreviation error = %v, want %v", err, ErrNoMatch)
+	}
+
+	// a and b share 10 body characters
+	if n := idx.MinUnique(); n != 11 {
+		t.Errorf("MinUnique() = %d, want 11", n)
+	}
+	if a.Short(11) == b.Short(11) || a.Short(10) != b.Short(10) {
+		t.Errorf("MinUnique() is not the minimum unambiguous length")
+	}
+	if n := NewIndex(a, c).MinUnique(); n != 1 {
+		t.Errorf("MinUnique() with distinct prefixes = %d, want 1", n)
+	}
+}
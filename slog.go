@@ -0,0 +1,213 @@
+//go:build go1.21
+
+package xuid
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+)
+
+// LogValue implements slog.LogValuer, logging x as a group holding its
+// prefix and its canonical form:
+//
+//	user.prefix=user user.id=user-h4nu2n-zu3f-dmnn-kguv-6f643nei
+func (x XUID) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("prefix", x.Prefix), slog.String("id", x.String()))
+}
+
+// RedactMode tells a RedactingHandler what to do with a logged XUID.
+type RedactMode int
+
+const (
+	// RedactKeep logs the XUID unchanged
+	RedactKeep RedactMode = iota
+	// RedactRemove replaces the id with a fixed marker, keeping the prefix
+	RedactRemove
+	// RedactHash replaces the XUID with a pseudonym derived with a keyed hash,
+	// so that records about the same ID can still be correlated
+	RedactHash
+)
+
+// redactedID replaces the id of XUIDs logged with RedactRemove
+const redactedID = "[redacted]"
+
+// LogPolicy configures a RedactingHandler.
+type LogPolicy struct {
+	// Prefixes maps prefixes to the mode applied to XUIDs carrying them
+	Prefixes map[string]RedactMode
+	// Default is the mode applied to other prefixes
+	Default RedactMode
+	// Key is the secret used by RedactHash. It must be set when RedactHash
+	// is used, and kept stable for pseudonyms to remain comparable.
+	Key []byte
+}
+
+// mode returns the mode applying to prefix
+func (p *LogPolicy) mode(prefix string) RedactMode {
+	if m, ok := p.Prefixes[prefix]; ok {
+		return m
+	}
+	return p.Default
+}
+
+// RedactingHandler is a slog.Handler rewriting the XUID and *XUID values
+// found in log attributes, including nested groups, according to a
+// LogPolicy before passing records to another handler.
+//
+// Slices and arrays of XUID or *XUID are logged as a list of strings, each
+// being the canonical form of the XUID after the policy was applied, or
+// prefix-[redacted] for RedactRemove. LogValuers are resolved by the handler
+// so that XUIDs they return are redacted as well.
+//
+// Only values of type XUID are recognized: IDs logged as plain strings, or
+// held in other containers such as maps, are passed through unchanged.
+type RedactingHandler struct {
+	next   slog.Handler
+	policy *LogPolicy
+}
+
+// NewRedactingHandler returns a handler applying policy to records before
+// passing them to next. It panics if the policy uses RedactHash without a
+// key.
+func NewRedactingHandler(next slog.Handler, policy LogPolicy) *RedactingHandler {
+	if len(policy.Key) == 0 {
+		uses := policy.Default == RedactHash
+		for _, m := range policy.Prefixes {
+			uses = uses || m == RedactHash
+		}
+		if uses {
+			panic("xuid: RedactHash requires a key")
+		}
+	}
+	return &RedactingHandler{next: next, policy: &policy}
+}
+
+// Enabled implements slog.Handler
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, nr)
+}
+
+// WithAttrs implements slog.Handler
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &RedactingHandler{next: h.next.WithAttrs(h.redactAttrs(attrs)), policy: h.policy}
+}
+
+// WithGroup implements slog.Handler
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), policy: h.policy}
+}
+
+func (h *RedactingHandler) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	res := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		res[i] = h.redactAttr(a)
+	}
+	return res
+}
+
+func (h *RedactingHandler) redactAttr(a slog.Attr) slog.Attr {
+	a.Value = h.redactValue(a.Value)
+	return a
+}
+
+// maxLogValuerDepth bounds the resolution of LogValuers returning other
+// LogValuers, as slog.Value.Resolve does
+const maxLogValuerDepth = 100
+
+// redactValue applies the policy to the XUIDs held by v, resolving
+// LogValuers on the way
+func (h *RedactingHandler) redactValue(v slog.Value) slog.Value {
+	for i := 0; v.Kind() == slog.KindLogValuer; i++ {
+		if r, ok := h.redactAny(v.Any()); ok {
+			return r
+		}
+		if i == maxLogValuerDepth {
+			// let slog report the error
+			return v.Resolve()
+		}
+		v = v.LogValuer().LogValue()
+	}
+
+	switch v.Kind() {
+	case slog.KindAny:
+		if r, ok := h.redactAny(v.Any()); ok {
+			return r
+		}
+	case slog.KindGroup:
+		return slog.GroupValue(h.redactAttrs(v.Group())...)
+	}
+	return v
+}
+
+// ptrXUIDType is the reflect.Type of *XUID
+var ptrXUIDType = reflect.PtrTo(xuidType)
+
+// redactAny applies the policy to v if it is a XUID, a *XUID or a slice or
+// array of them, and reports whether it was
+func (h *RedactingHandler) redactAny(v any) (slog.Value, bool) {
+	switch x := v.(type) {
+	case XUID:
+		return h.redact(x), true
+	case *XUID:
+		if x == nil {
+			return slog.AnyValue(x), true
+		}
+		return h.redact(*x), true
+	}
+
+	rv := reflect.ValueOf(v)
+	if k := rv.Kind(); k != reflect.Slice && k != reflect.Array {
+		return slog.Value{}, false
+	}
+	if t := rv.Type().Elem(); t != xuidType && t != ptrXUIDType {
+		return slog.Value{}, false
+	}
+	list := make([]string, rv.Len())
+	for i := range list {
+		e := rv.Index(i)
+		if e.Kind() == reflect.Pointer {
+			if e.IsNil() {
+				continue
+			}
+			e = e.Elem()
+		}
+		list[i] = h.redactString(e.Interface().(XUID))
+	}
+	return slog.AnyValue(list), true
+}
+
+// redactString returns the string logged for x as part of a list
+func (h *RedactingHandler) redactString(x XUID) string {
+	switch h.policy.mode(x.Prefix) {
+	case RedactRemove:
+		if x.Prefix == "" {
+			return redactedID
+		}
+		return x.Prefix + "-" + redactedID
+	case RedactHash:
+		return XUID{Prefix: x.Prefix, UUID: keyedUUID(h.policy.Key, x)}.String()
+	}
+	return x.String()
+}
+
+// redact returns the value logged for x
+func (h *RedactingHandler) redact(x XUID) slog.Value {
+	switch h.policy.mode(x.Prefix) {
+	case RedactRemove:
+		return slog.GroupValue(slog.String("prefix", x.Prefix), slog.String("id", redactedID))
+	case RedactHash:
+		return XUID{Prefix: x.Prefix, UUID: keyedUUID(h.policy.Key, x)}.LogValue()
+	}
+	return x.LogValue()
+}
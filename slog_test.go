@@ -0,0 +1,110 @@
+//go:build go1.21
+
+package xuid
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime}))
+	log.Info("hello", "user", *MustParse("user-h4nu2n-zu3f-dmnn-kguv-6f643nei"))
+
+	want := "level=INFO msg=hello user.prefix=user user.id=user-h4nu2n-zu3f-dmnn-kguv-6f643nei\n"
+	if got := buf.String(); got != want {
+		t.Errorf("log output = %q, want %q", got, want)
+	}
+}
+
+func TestRedactingHandler(t *testing.T) {
+	key := []byte("secret")
+	user := MustParse("user-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	order := *MustParse("order-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba")
+	sess := *MustParse("sess-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca")
+	hashed := XUID{Prefix: "user", UUID: keyedUUID(key, *user)}
+
+	var buf bytes.Buffer
+	h := NewRedactingHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime}), LogPolicy{
+		Prefixes: map[string]RedactMode{"user": RedactHash, "order": RedactKeep},
+		Default:  RedactRemove,
+		Key:      key,
+	})
+	log := slog.New(h).With("actor", user)
+	log.Info("hello", "order", order, slog.Group("req", "session", sess), "nil", (*XUID)(nil))
+
+	got := buf.String()
+	for _, want := range []string{
+		"actor.id=" + hashed.String(),
+		"order.id=" + order.String(),
+		"req.session.prefix=sess req.session.id=[redacted]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, user.String()) || strings.Contains(got, sess.String()) {
+		t.Errorf("log output = %q leaks a redacted ID", got)
+	}
+	if hashed.UUID.Version() != 8 {
+		t.Errorf("hashed version = %d, want 8", hashed.UUID.Version())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewRedactingHandler() without key did not panic")
+		}
+	}()
+	NewRedactingHandler(h, LogPolicy{Default: RedactHash})
+}
+
+// userValuer is a LogValuer returning a XUID
+type userValuer struct{ id XUID }
+
+func (v userValuer) LogValue() slog.Value {
+	return slog.AnyValue(v.id)
+}
+
+func TestRedactingHandlerContainers(t *testing.T) {
+	key := []byte("secret")
+	user := *MustParse("user-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	order := *MustParse("order-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba")
+	hashed := XUID{Prefix: "order", UUID: keyedUUID(key, order)}
+
+	var buf bytes.Buffer
+	h := NewRedactingHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{ReplaceAttr: dropTime}), LogPolicy{
+		Prefixes: map[string]RedactMode{"order": RedactHash},
+		Default:  RedactRemove,
+		Key:      key,
+	})
+	slog.New(h).Info("hello",
+		"ids", []XUID{user, order},
+		"ptrs", [2]*XUID{&user, nil},
+		"valuer", userValuer{user},
+	)
+
+	got := buf.String()
+	for _, want := range []string{
+		"ids=\"[user-[redacted] " + hashed.String() + "]\"",
+		"ptrs=\"[user-[redacted] ]\"",
+		"valuer.prefix=user valuer.id=[redacted]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, user.String()) || strings.Contains(got, order.String()) {
+		t.Errorf("log output = %q leaks a redacted ID", got)
+	}
+}
+
+// dropTime removes the time from log records for reproducible output
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
@@ -0,0 +1,130 @@
+package xuid
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultStateReserve is the range reserved ahead by generators using a
+// StateStore when no reservation is given
+const defaultStateReserve = time.Second
+
+// StateStore persists the high-water mark of a time based generator, so that
+// a restarted process never emits IDs lower than the ones emitted before it
+// stopped, even if the clock was set back in between.
+//
+// The high-water mark is a Unix timestamp in milliseconds. A generator using
+// a StateStore guarantees that every ID it emitted has a timestamp strictly
+// lower than the last saved value.
+type StateStore interface {
+	// Load returns the last saved high-water mark, or 0 if none was saved.
+	Load() (int64, error)
+
+	// Save durably records a new high-water mark.
+	Save(ms int64) error
+}
+
+// WithStateStore makes a MonotonicGenerator or a NodeGenerator persist its
+// high-water mark in s. To avoid writing the state for every ID, the generator reserves a time
+// range ahead of the current clock (one second if reserve is zero) and only
+// saves again once it reaches the end of it. After a restart, IDs start at
+// the end of the last reserved range if the clock is behind it.
+func WithStateStore(s StateStore, reserve time.Duration) GeneratorOption {
+	return func(c *generatorConfig) {
+		c.state = s
+		c.reserve = reserve
+	}
+}
+
+// reserveMs returns the reservation in milliseconds, at least one
+func (c *generatorConfig) reserveMs() int64 {
+	r := c.reserve
+	if r <= 0 {
+		r = defaultStateReserve
+	}
+	if ms := r.Milliseconds(); ms > 1 {
+		return ms
+	}
+	return 1
+}
+
+// FileStateStore is a StateStore keeping the high-water mark in a file.
+//
+// The state is written atomically by renaming a temporary file over the
+// previous one. While the store is open, it holds an exclusive lock on a
+// companion file with the ".lock" suffix so that two processes cannot share
+// the same state. Locking is only available on unix systems.
+type FileStateStore struct {
+	path string
+	lock *os.File
+}
+
+// OpenFileStateStore opens the state file at path, which does not need to
+// exist yet, and locks it. It returns ErrStateLocked if the state is already
+// in use by another store.
+func OpenFileStateStore(path string) (*FileStateStore, error) {
+	lock, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return &FileStateStore{path: path, lock: lock}, nil
+}
+
+// Load implements StateStore
+func (s *FileStateStore) Load() (int64, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// Save implements StateStore
+func (s *FileStateStore) Save(ms int64) error {
+	dir, name := filepath.Split(s.path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, name+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.WriteString(strconv.FormatInt(ms, 10) + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+
+	// make the rename durable, not all systems support syncing a directory
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// Close releases the lock held on the state.
+func (s *FileStateStore) Close() error {
+	return s.lock.Close()
+}
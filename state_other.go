@@ -0,0 +1,10 @@
+//go:build !unix
+
+package xuid
+
+import "os"
+
+// lockFile is a no-op on systems without flock
+func lockFile(f *os.File) error {
+	return nil
+}
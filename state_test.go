@@ -0,0 +1,101 @@
+package xuid
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestFileStateStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xuid.state")
+
+	s, err := OpenFileStateStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStateStore() error = %v", err)
+	}
+	if ms, err := s.Load(); err != nil || ms != 0 {
+		t.Errorf("Load() on missing file = %d, %v, want 0, nil", ms, err)
+	}
+	if err := s.Save(1700000000000); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if ms, err := s.Load(); err != nil || ms != 1700000000000 {
+		t.Errorf("Load() = %d, %v, want 1700000000000, nil", ms, err)
+	}
+
+	if runtime.GOOS != "windows" {
+		if _, err := OpenFileStateStore(path); !errors.Is(err, ErrStateLocked) {
+			t.Errorf("OpenFileStateStore() on locked state error = %v, want %v", err, ErrStateLocked)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	s, err = OpenFileStateStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStateStore() after Close() error = %v", err)
+	}
+	s.Close()
+}
+
+func TestMonotonicGeneratorRestart(t *testing.T) {
+	testGeneratorRestart(t, func(opts ...GeneratorOption) Generator {
+		return NewMonotonicGenerator(opts...)
+	})
+}
+
+func TestNodeGeneratorRestart(t *testing.T) {
+	testGeneratorRestart(t, func(opts ...GeneratorOption) Generator {
+		g, err := NewNodeGenerator(7, DefaultNodeLayout, opts...)
+		if err != nil {
+			t.Fatalf("NewNodeGenerator() error = %v", err)
+		}
+		return g
+	})
+}
+
+// testGeneratorRestart checks that a generator using a FileStateStore keeps
+// emitting increasing IDs after a restart with the clock set back
+func testGeneratorRestart(t *testing.T, newGen func(opts ...GeneratorOption) Generator) {
+	path := filepath.Join(t.TempDir(), "xuid.state")
+	now := time.UnixMilli(1700000000000)
+	clock := func() time.Time { return now }
+
+	// first process run
+	s, err := OpenFileStateStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStateStore() error = %v", err)
+	}
+	g := newGen(WithClock(clock), WithStateStore(s, 10*time.Second))
+	var last XUID
+	for i := 0; i < 100; i++ {
+		if last, err = g.New("evt"); err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		now = now.Add(time.Millisecond)
+	}
+	s.Close()
+
+	// the process restarts after the clock was set back
+	now = now.Add(-time.Hour)
+	s, err = OpenFileStateStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStateStore() error = %v", err)
+	}
+	defer s.Close()
+	g = newGen(WithClock(clock), WithStateStore(s, 10*time.Second))
+	for i := 0; i < 100; i++ {
+		x, err := g.New("evt")
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if bytes.Compare(x.UUID[:], last.UUID[:]) <= 0 {
+			t.Fatalf("New() after restart %s is not greater than %s", x, last)
+		}
+		last = x
+	}
+}
@@ -0,0 +1,203 @@
+package xuid
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxSuggestDistance is the maximum edit distance between an unknown prefix
+// and a registered prefix for the latter to be suggested
+const maxSuggestDistance = 2
+
+var (
+	prefixesLk sync.RWMutex
+	prefixes   = make(map[string]bool)
+)
+
+// RegisterPrefix declares prefixes used by the application. Registered
+// prefixes are suggested by ParsePrefix errors when a XUID carries a prefix
+// that looks like a typo of one of them.
+func RegisterPrefix(p ...string) {
+	prefixesLk.Lock()
+	defer prefixesLk.Unlock()
+	for _, s := range p {
+		prefixes[s] = true
+	}
+}
+
+// RegisteredPrefixes returns the sorted list of registered prefixes.
+func RegisteredPrefixes() []string {
+	prefixesLk.RLock()
+	defer prefixesLk.RUnlock()
+	res := make([]string, 0, len(prefixes))
+	for p := range prefixes {
+		res = append(res, p)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// suggestPrefix returns the registered prefixes closest to p by edit
+// distance, or nil if p is registered or no prefix is close enough
+func suggestPrefix(p string) []string {
+	prefixesLk.RLock()
+	defer prefixesLk.RUnlock()
+	if prefixes[p] {
+		return nil
+	}
+	var res []string
+	best := maxSuggestDistance + 1
+	for r := range prefixes {
+		d := editDistance(p, r)
+		if d < best {
+			best, res = d, nil
+		}
+		if d == best {
+			res = append(res, r)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// minInt returns the smallest of its arguments
+func minInt(v ...int) int {
+	m := v[0]
+	for _, x := range v[1:] {
+		if x < m {
+			m = x
+		}
+	}
+	return m
+}
+
+// PrefixError is returned by ParsePrefix and Walk when the prefix of the
+// XUID does not match the expected one. It wraps ErrBadPrefix.
+type PrefixError struct {
+	// Got is the prefix found in the XUID
+	Got string
+	// Want is the expected prefix
+	Want string
+	// Suggestions lists the registered prefixes closest to Got, if Got is
+	// not itself registered
+	Suggestions []string
+}
+
+func (e *PrefixError) Error() string {
+	msg := fmt.Sprintf("%s, expected prefix %s", ErrBadPrefix, e.Want)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(" (got %s, did you mean %s?)", e.Got, strings.Join(e.Suggestions, " or "))
+	}
+	return msg
+}
+
+// Unwrap returns ErrBadPrefix
+func (e *PrefixError) Unwrap() error {
+	return ErrBadPrefix
+}
+
+// ParseError is returned by Parse when a string has the layout of a XUID but
+// its body contains characters outside of the base32 alphabet.
+type ParseError struct {
+	// Input is the string being parsed
+	Input string
+	// Offset is the byte offset of the first invalid character in Input
+	Offset int
+	// Suggestions lists, when the body contains a single invalid character,
+	// the canonical XUIDs obtained by replacing it. Replacements for
+	// commonly confused characters come first.
+	Suggestions []string
+	// Err is the underlying decoding error
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("xuid: invalid character %q at offset %d", e.Input[e.Offset], e.Offset)
+	switch n := len(e.Suggestions); {
+	case n == 1:
+		msg += fmt.Sprintf(", did you mean %s?", e.Suggestions[0])
+	case n > 1:
+		msg += fmt.Sprintf(", did you mean %s (or %d other candidates)?", e.Suggestions[0], n-1)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying decoding error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError builds a ParseError for s, whose body starts at offset
+// start, if it contains invalid characters. Otherwise err is returned as is.
+func newParseError(s string, start int, err error) error {
+	lower := strings.ToLower(s)
+	var bad []int
+	for i := start; i < len(lower); i++ {
+		if c := lower[i]; c != '-' && !isBase32(c) {
+			bad = append(bad, i)
+		}
+	}
+	if len(bad) == 0 {
+		return err
+	}
+
+	res := &ParseError{Input: s, Offset: bad[0], Err: err}
+	if len(bad) > 1 {
+		return res
+	}
+
+	// try every replacement of the invalid character, confusables first
+	cand := confusables[lower[bad[0]]] + "abcdefghijklmnopqrstuvwxyz234567"
+	seen := make(map[byte]bool)
+	for i := 0; i < len(cand); i++ {
+		c := cand[i]
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		try := lower[:bad[0]] + string(c) + lower[bad[0]+1:]
+		if x, err := Parse(try); err == nil && x.String() == try {
+			res.Suggestions = append(res.Suggestions, try)
+		}
+	}
+	return res
+}
+
+// Suggestions returns the suggestions carried by err, which may be a
+// *PrefixError or a *ParseError, possibly wrapped. It returns nil if err
+// carries no suggestions.
+func Suggestions(err error) []string {
+	var pe *PrefixError
+	if errors.As(err, &pe) {
+		return pe.Suggestions
+	}
+	var se *ParseError
+	if errors.As(err, &se) {
+		return se.Suggestions
+	}
+	return nil
+}
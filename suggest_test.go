@@ -0,0 +1,123 @@
+package xuid
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// isolatePrefixes gives the test an empty prefix registry, restoring the
+// previous one when the test ends
+func isolatePrefixes(t testing.TB) {
+	prefixesLk.Lock()
+	saved := prefixes
+	prefixes = make(map[string]bool)
+	prefixesLk.Unlock()
+	t.Cleanup(func() {
+		prefixesLk.Lock()
+		prefixes = saved
+		prefixesLk.Unlock()
+	})
+}
+
+func TestPrefixSuggestions(t *testing.T) {
+	isolatePrefixes(t)
+	RegisterPrefix("user", "order", "shell")
+
+	_, err := ParsePrefix("usr-h4nu2n-zu3f-dmnn-kguv-6f643nei", "shell")
+	if !errors.Is(err, ErrBadPrefix) {
+		t.Fatalf("ParsePrefix() error = %v, want %v", err, ErrBadPrefix)
+	}
+	var pe *PrefixError
+	if !errors.As(err, &pe) {
+		t.Fatalf("ParsePrefix() error is %T, want *PrefixError", err)
+	}
+	if pe.Got != "usr" || pe.Want != "shell" || !reflect.DeepEqual(pe.Suggestions, []string{"user"}) {
+		t.Errorf("ParsePrefix() error = %+v", pe)
+	}
+	if want := "xuid: bad prefix, expected prefix shell (got usr, did you mean user?)"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	// registered prefixes and unrelated ones get no suggestion
+	for _, s := range []string{"order-h4nu2n-zu3f-dmnn-kguv-6f643nei", "zzzzz-h4nu2n-zu3f-dmnn-kguv-6f643nei"} {
+		_, err := ParsePrefix(s, "shell")
+		if got := Suggestions(err); got != nil {
+			t.Errorf("Suggestions() for %s = %v, want none", s, got)
+		}
+	}
+
+	if p := RegisteredPrefixes(); !reflect.DeepEqual(p, []string{"order", "shell", "user"}) {
+		t.Errorf("RegisteredPrefixes() = %v", p)
+	}
+}
+
+func TestParseSuggestions(t *testing.T) {
+	// the 8 in the body is not a base32 character
+	_, err := Parse("shell-h4nu2n-zu3f-dmnn-kguv-6f643ne8")
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Parse() error = %v, want a *ParseError", err)
+	}
+	if pe.Offset != 35 {
+		t.Errorf("ParseError.Offset = %d, want 35", pe.Offset)
+	}
+	// the last character only holds 3 bits, so only 8 candidates are canonical
+	if len(pe.Suggestions) != 8 {
+		t.Errorf("ParseError.Suggestions = %v, want 8 candidates", pe.Suggestions)
+	}
+	for _, s := range pe.Suggestions {
+		if x, err := Parse(s); err != nil || x.String() != s {
+			t.Errorf("suggestion %q is not a canonical XUID", s)
+		}
+	}
+
+	// confusable replacements come first
+	_, err = Parse("shell-h4nu2n-zu3f-dmnn-kgu0-6f643nei")
+	if s := Suggestions(err); len(s) != 32 || s[0] != "shell-h4nu2n-zu3f-dmnn-kguo-6f643nei" {
+		t.Errorf("Suggestions() = %v", s)
+	}
+	if !strings.Contains(err.Error(), "did you mean shell-h4nu2n-zu3f-dmnn-kguo-6f643nei") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	// several invalid characters give no suggestion
+	_, err = Parse("shell-h4nu2n-zu3f-dmnn-kg00-6f643nei")
+	if !errors.As(err, &pe) || pe.Suggestions != nil {
+		t.Errorf("Parse() with two invalid characters error = %v", err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"user", "user", 0},
+		{"usr", "user", 1},
+		{"uesr", "user", 2},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestIsolatePrefixes(t *testing.T) {
+	before := RegisteredPrefixes()
+	t.Run("Register", func(t *testing.T) {
+		isolatePrefixes(t)
+		RegisterPrefix("tmp")
+		if got := RegisteredPrefixes(); !reflect.DeepEqual(got, []string{"tmp"}) {
+			t.Errorf("RegisteredPrefixes() = %v, want [tmp]", got)
+		}
+	})
+	if got := RegisteredPrefixes(); !reflect.DeepEqual(got, before) {
+		t.Errorf("RegisteredPrefixes() after the test = %v, want %v", got, before)
+	}
+}
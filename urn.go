@@ -0,0 +1,170 @@
+package xuid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// URN returns the XUID as a Uniform Resource Name, in the form
+// urn:xuid:<prefix>:<body>, such as
+// urn:xuid:shell:h4nu2n-zu3f-dmnn-kguv-6f643nei. Characters of the prefix
+// other than letters, digits and "-._~" are percent-encoded. Like String,
+// URN uses at most the first 5 characters of the prefix, in lowercase.
+//
+// XUIDs without a prefix are returned as a standard urn:uuid: URN, as
+// defined by RFC 9562.
+func (x XUID) URN() string {
+	if x.Prefix == "" {
+		return x.UUID.URN()
+	}
+	s := x.String()
+	return "urn:xuid:" + escapeNSS(s[:len(s)-31]) + ":" + s[len(s)-30:]
+}
+
+// ParseURN parses a URN in the form returned by URN. It accepts urn:uuid:
+// URNs, returned as XUIDs without a prefix, and urn:xuid: URNs with or
+// without a prefix.
+//
+// Following RFC 8141, the "urn" scheme and the namespace identifier are case
+// insensitive, and the optional r-component, q-component and f-component
+// (starting with "?+", "?=" and "#") are ignored. It returns ErrBadURN if the
+// input is not a valid URN of one of these namespaces, or if the prefix of a
+// urn:xuid: URN is empty or longer than 5 bytes, which Parse does not accept
+// either.
+func ParseURN(s string) (*XUID, error) {
+	if len(s) < 4 || !strings.EqualFold(s[:4], "urn:") {
+		return nil, fmt.Errorf("%w: missing urn: scheme", ErrBadURN)
+	}
+	s = s[4:]
+
+	// strip the components that are not part of the name
+	if p := strings.IndexByte(s, '#'); p >= 0 {
+		s = s[:p]
+	}
+	if p := strings.Index(s, "?+"); p >= 0 {
+		s = s[:p]
+	} else if p := strings.Index(s, "?="); p >= 0 {
+		s = s[:p]
+	}
+
+	p := strings.IndexByte(s, ':')
+	if p < 0 {
+		return nil, fmt.Errorf("%w: missing namespace", ErrBadURN)
+	}
+	nid, nss := strings.ToLower(s[:p]), s[p+1:]
+	if !validNSS(nss) {
+		return nil, fmt.Errorf("%w: invalid namespace specific string %q", ErrBadURN, nss)
+	}
+
+	switch nid {
+	case "uuid":
+		u, err := uuid.Parse(nss)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrBadURN, err)
+		}
+		return &XUID{UUID: u}, nil
+	case "xuid":
+		var pfx string
+		if p := strings.IndexByte(nss, ':'); p >= 0 {
+			pfx, nss = unescapeNSS(nss[:p]), nss[p+1:]
+			if pfx == "" || len(pfx) > 5 {
+				return nil, fmt.Errorf("%w: invalid prefix %q", ErrBadURN, pfx)
+			}
+		}
+		if len(nss) != 30 {
+			return nil, fmt.Errorf("%w: invalid XUID body %q", ErrBadURN, nss)
+		}
+		x, err := Parse(nss)
+		if err != nil {
+			// the offset and suggestions of a *ParseError refer to the
+			// body alone, so they are not passed on
+			return nil, fmt.Errorf("%w: invalid XUID body %q", ErrBadURN, nss)
+		}
+		x.Prefix = pfx
+		return x, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported namespace %q", ErrBadURN, nid)
+	}
+}
+
+// isUnreserved reports whether c is an unreserved character as defined by
+// RFC 3986
+func isUnreserved(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.IndexByte("-._~", c) >= 0
+}
+
+// validNSS reports whether s is a valid RFC 8141 namespace specific string:
+// a non empty sequence of pchar and "/", not starting with "/"
+func validNSS(s string) bool {
+	if s == "" || s[0] == '/' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isUnreserved(c), strings.IndexByte("!$&'()*+,;=:@/", c) >= 0:
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return false
+			}
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isHex reports whether c is an hexadecimal digit
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// escapeNSS percent-encodes all characters of s that are not unreserved
+func escapeNSS(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+	return b.String()
+}
+
+// unescapeNSS decodes the percent-encoded characters of s, which must have
+// been validated with validNSS
+func unescapeNSS(s string) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+		i += 2
+	}
+	return b.String()
+}
+
+// unhex returns the value of the hexadecimal digit c
+func unhex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
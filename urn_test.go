@@ -0,0 +1,89 @@
+package xuid
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestURN(t *testing.T) {
+	tests := []struct {
+		name string
+		x    XUID
+		want string
+	}{
+		{"Prefixed", *MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei"), "urn:xuid:shell:h4nu2n-zu3f-dmnn-kguv-6f643nei"},
+		{"No prefix", *MustParse("h4nu2n-zu3f-dmnn-kguv-6f643nei"), "urn:uuid:3f1b4d37-34d9-46c6-b546-a57c5f736d22"},
+		{"Escaped prefix", XUID{Prefix: "a:b", UUID: MustParse("null-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa").UUID}, "urn:xuid:a%3Ab:aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.x.URN(); got != tt.want {
+				t.Errorf("URN() = %q, want %q", got, tt.want)
+			}
+			got, err := ParseURN(tt.want)
+			if err != nil {
+				t.Fatalf("ParseURN(%q) error = %v", tt.want, err)
+			}
+			if !got.Equals(tt.x) {
+				t.Errorf("ParseURN(%q) = %+v, want %+v", tt.want, got, tt.x)
+			}
+		})
+	}
+}
+
+func TestURNMatchesString(t *testing.T) {
+	u := MustParse("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei").UUID
+	x := XUID{Prefix: "Toolongprefix", UUID: u}
+	if got, want := x.URN(), "urn:xuid:toolo:h4nu2n-zu3f-dmnn-kguv-6f643nei"; got != want {
+		t.Errorf("URN() = %q, want %q", got, want)
+	}
+}
+
+func TestParseURN(t *testing.T) {
+	valid := []string{
+		"URN:XUID:shell:H4NU2N-ZU3F-DMNN-KGUV-6F643NEI",
+		"urn:xuid:shell:h4nu2n-zu3f-dmnn-kguv-6f643nei?+resolve",
+		"urn:xuid:shell:h4nu2n-zu3f-dmnn-kguv-6f643nei?=q#frag",
+		"urn:xuid:shell:h4nu2n-zu3f-dmnn-kguv-6f643nei#frag",
+	}
+	for _, s := range valid {
+		x, err := ParseURN(s)
+		if err != nil {
+			t.Errorf("ParseURN(%q) error = %v", s, err)
+			continue
+		}
+		if x.String() != "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei" {
+			t.Errorf("ParseURN(%q) = %s", s, x)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"urn:",
+		"urn:isbn:0451450523",
+		"urn:xuid:shell:h4nu2n",
+		"urn:xuid:sh ll:h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"urn:xuid:sh%zzll:h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"urn:uuid:not-a-uuid",
+		"shell-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"urn:xuid:shell:h4nu2n-zu3f-dmnn-kguv-6f643ne0",
+		"urn:xuid:toolongprefix:h4nu2n-zu3f-dmnn-kguv-6f643nei",
+		"urn:xuid::h4nu2n-zu3f-dmnn-kguv-6f643nei",
+	}
+	for _, s := range invalid {
+		if _, err := ParseURN(s); !errors.Is(err, ErrBadURN) {
+			t.Errorf("ParseURN(%q) error = %v, want %v", s, err, ErrBadURN)
+		}
+	}
+
+	// suggestions from Parse refer to the body alone and are not exposed
+	if _, err := ParseURN("urn:xuid:shell:h4nu2n-zu3f-dmnn-kguv-6f643ne0"); Suggestions(err) != nil {
+		t.Errorf("ParseURN() suggestions = %q, want none", Suggestions(err))
+	}
+
+	x, corr, err := ParseLenient(" urn:xuid:shell:h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	if err != nil || x.String() != "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei" || len(corr) != 1 {
+		t.Errorf("ParseLenient() of URN = %v, %v, %v", x, corr, err)
+	}
+}
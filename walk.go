@@ -0,0 +1,182 @@
+package xuid
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Walk calls fn for every XUID reachable from v, which must be a non-nil
+// pointer. It follows pointers, structs, slices, arrays, maps and
+// interfaces, so XUID, *XUID and []XUID fields are all visited, at any depth.
+//
+// The path passed to fn locates the XUID from v using Go syntax, such as
+// Items[2].Owner or Refs["parent"], and is empty for v itself. fn may modify
+// the XUID it is given to replace it in place, including in map values and
+// interfaces. Each XUID is visited once, even if it is reachable through
+// several pointers, and pointer cycles are not followed. Unexported struct
+// fields and map keys are skipped.
+//
+// The xuid struct tag controls how fields are walked:
+//
+//	Owner XUID   `xuid:"prefix=user"` // non-zero IDs must have the prefix user
+//	Refs  []XUID `xuid:"prefix=doc"`  // applies to every element
+//	Raw   XUID   `xuid:"-"`           // field is skipped
+//
+// If a non-zero XUID does not carry the expected prefix, Walk stops and
+// returns a *PrefixError, wrapped with the path, before calling fn for it.
+// If fn returns an error, Walk stops and returns it.
+func Walk(v any, fn func(path string, id *XUID) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("%w: got %T", ErrNotPointer, v)
+	}
+	w := &walker{fn: fn, seen: make(map[*XUID]bool), ptrs: make(map[ptrKey]bool)}
+	return w.walk(rv, "", "")
+}
+
+// xuidType is the reflect.Type of XUID
+var xuidType = reflect.TypeOf(XUID{})
+
+// ptrKey identifies a pointer already followed by a walker
+type ptrKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// walker holds the state of a call to Walk
+type walker struct {
+	fn   func(path string, id *XUID) error
+	seen map[*XUID]bool // XUIDs already visited
+	ptrs map[ptrKey]bool
+}
+
+// walk visits v, located at path, whose XUIDs must carry the prefix want
+// if not empty
+func (w *walker) walk(v reflect.Value, path, want string) error {
+	if v.Type() == xuidType {
+		if !v.CanSet() {
+			return nil
+		}
+		x := v.Addr().Interface().(*XUID)
+		if w.seen[x] {
+			return nil
+		}
+		w.seen[x] = true
+		if want != "" && x.Prefix != want && x.UUID != (XUID{}).UUID {
+			return fmt.Errorf("%s: %w", path, &PrefixError{Got: x.Prefix, Want: want, Suggestions: suggestPrefix(x.Prefix)})
+		}
+		return w.fn(path, x)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		k := ptrKey{v.Pointer(), v.Type()}
+		if w.ptrs[k] {
+			return nil
+		}
+		w.ptrs[k] = true
+		return w.walk(v.Elem(), path, want)
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return nil
+		}
+		e := v.Elem()
+		if e.Kind() == reflect.Pointer {
+			return w.walk(e, path, want)
+		}
+		// the value held by an interface is not addressable, walk a copy
+		c := reflect.New(e.Type()).Elem()
+		c.Set(e)
+		if err := w.walk(c, path, want); err != nil {
+			return err
+		}
+		v.Set(c)
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			skip, pfx, err := parseWalkTag(f)
+			if err != nil {
+				return err
+			}
+			if skip {
+				continue
+			}
+			p := f.Name
+			if path != "" {
+				p = path + "." + f.Name
+			}
+			if err := w.walk(v.Field(i), p, pfx); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if !mayHoldXUID(v.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), path+"["+strconv.Itoa(i)+"]", want); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() || !v.CanSet() || !mayHoldXUID(v.Type().Elem()) {
+			return nil
+		}
+		it := v.MapRange()
+		for it.Next() {
+			c := reflect.New(v.Type().Elem()).Elem()
+			c.Set(it.Value())
+			if err := w.walk(c, path+"["+mapKeyString(it.Key())+"]", want); err != nil {
+				return err
+			}
+			v.SetMapIndex(it.Key(), c)
+		}
+	}
+	return nil
+}
+
+// parseWalkTag parses the xuid tag of f, returning whether the field is
+// skipped and the prefix its XUIDs must carry
+func parseWalkTag(f reflect.StructField) (skip bool, prefix string, err error) {
+	tag, ok := f.Tag.Lookup("xuid")
+	if !ok || tag == "" {
+		return false, "", nil
+	}
+	if tag == "-" {
+		return true, "", nil
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		if strings.HasPrefix(opt, "prefix=") && len(opt) > len("prefix=") {
+			prefix = opt[len("prefix="):]
+			continue
+		}
+		return false, "", fmt.Errorf("xuid: invalid struct tag %q on field %s", tag, f.Name)
+	}
+	return false, prefix, nil
+}
+
+// mapKeyString formats a map key for a Walk path
+func mapKeyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return strconv.Quote(k.String())
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+// mayHoldXUID reports whether values of type t may contain a XUID
+func mayHoldXUID(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package xuid
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	type item struct {
+		ID   XUID `xuid:"prefix=item"`
+		Ref  *XUID
+		Tags []XUID
+	}
+	type payload struct {
+		Owner  XUID `xuid:"prefix=user"`
+		Items  []item
+		ByName map[string]XUID
+		Raw    XUID `xuid:"-"`
+		Unset  XUID `xuid:"prefix=user"`
+		Self   *payload
+	}
+
+	owner := *MustParse("user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba")
+	ids := testIDs(t, "item", 3)
+	ref := ids[0]
+	v := &payload{
+		Owner:  owner,
+		Items:  []item{{ID: ids[0], Ref: &ref, Tags: []XUID{ids[1]}}, {ID: ids[2], Ref: &ref}},
+		ByName: map[string]XUID{"a": owner},
+		Raw:    *MustParse("raw-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca"),
+	}
+	v.Self = v
+
+	var paths []string
+	err := Walk(v, func(path string, id *XUID) error {
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	want := []string{"Owner", "Items[0].ID", "Items[0].Ref", "Items[0].Tags[0]", "Items[1].ID", `ByName["a"]`, "Unset"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Walk() paths = %q, want %q", paths, want)
+	}
+}
+
+func TestWalkReplace(t *testing.T) {
+	x := *MustParse("user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba")
+	y := *MustParse("user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca")
+	v := &struct {
+		A XUID
+		M map[int]XUID
+		I any
+	}{A: x, M: map[int]XUID{1: x}, I: x}
+
+	err := Walk(v, func(_ string, id *XUID) error {
+		*id = y
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if !v.A.Equals(y) || !v.M[1].Equals(y) || !v.I.(XUID).Equals(y) {
+		t.Errorf("Walk() did not replace all IDs: %+v", v)
+	}
+
+	root := x
+	if err := Walk(&root, func(path string, id *XUID) error {
+		if path != "" {
+			t.Errorf("Walk() root path = %q, want empty", path)
+		}
+		*id = y
+		return nil
+	}); err != nil || !root.Equals(y) {
+		t.Errorf("Walk() on a XUID = %s, %v, want %s", root, err, y)
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	type doc struct {
+		Owner []XUID `xuid:"prefix=user"`
+	}
+	v := &doc{Owner: []XUID{*MustParse("user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba"), *MustParse("usr-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba")}}
+	err := Walk(v, func(string, *XUID) error { return nil })
+	var pe *PrefixError
+	if !errors.As(err, &pe) || pe.Got != "usr" || pe.Want != "user" {
+		t.Errorf("Walk() error = %v, want a *PrefixError for usr", err)
+	}
+	if !errors.Is(err, ErrBadPrefix) {
+		t.Errorf("Walk() error = %v, want %v", err, ErrBadPrefix)
+	}
+
+	stop := errors.New("stop")
+	if err := Walk(v, func(string, *XUID) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("Walk() error = %v, want %v", err, stop)
+	}
+	if err := Walk(*v, func(string, *XUID) error { return nil }); !errors.Is(err, ErrNotPointer) {
+		t.Errorf("Walk() error = %v, want %v", err, ErrNotPointer)
+	}
+
+	bad := &struct {
+		ID XUID `xuid:"prefx=user"`
+	}{}
+	if err := Walk(bad, func(string, *XUID) error { return nil }); err == nil {
+		t.Errorf("Walk() with an invalid tag did not fail")
+	}
+}
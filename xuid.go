@@ -7,8 +7,8 @@
 package xuid
 
 import (
+	"bytes"
 	"encoding/base32"
-	"fmt"
 	"strings"
 
 	"github.com/google/uuid"
@@ -41,6 +41,7 @@ func (x XUID) String() string {
 		b = b[pfxLn:]
 		b[0] = '-'
 		b = b[1:]
+		pfxLn++
 	}
 
 	// Format the base32 encoded UUID with hyphens in the same positions as a regular UUID
@@ -55,7 +56,7 @@ func (x XUID) String() string {
 	copy(b[22:], dst[18:]) // Final 8 chars
 
 	// Return the string representation, ensuring it's lowercase
-	return strings.ToLower(string(final[:31+pfxLn]))
+	return strings.ToLower(string(final[:30+pfxLn]))
 }
 
 // Equals compares two XUIDs and returns true if they are equal,
@@ -64,6 +65,19 @@ func (x XUID) Equals(y XUID) bool {
 	return x == y
 }
 
+// Compare returns an integer comparing two XUIDs, first by prefix and then by
+// the bytes of their UUID. The result is 0 if x == y, -1 if x < y and +1 if
+// x > y.
+func (x XUID) Compare(y XUID) int {
+	if x.Prefix != y.Prefix {
+		if x.Prefix < y.Prefix {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(x.UUID[:], y.UUID[:])
+}
+
 // Parse parses a string representation and returns the resulting XUID.
 // It can handle XUID formatted strings as well as standard UUIDs.
 // For XUIDs, it supports both prefixed and non-prefixed formats.
@@ -71,6 +85,9 @@ func (x XUID) Equals(y XUID) bool {
 // If the input string doesn't conform to XUID format, Parse will attempt
 // to interpret it as a standard UUID and assign an empty prefix.
 //
+// If the input has the layout of a XUID but contains characters outside of
+// the base32 alphabet, the error is a *ParseError which may carry suggestions.
+//
 // Returns the parsed XUID and any error encountered.
 func Parse(s string) (*XUID, error) {
 	// XUID length can be:
@@ -113,7 +130,7 @@ func Parse(s string) (*XUID, error) {
 	// Decode the base32 representation back to UUID bytes
 	_, err := b32enc.Decode(data[:], []byte(strings.ToUpper(strings.Join(parts, ""))))
 	if err != nil {
-		return nil, err
+		return nil, newParseError(s, l-30, err)
 	}
 
 	return &XUID{Prefix: pfx, UUID: data}, nil
@@ -128,7 +145,8 @@ func MustParse(s string) *XUID {
 // ParsePrefix parses a XUID string and verifies that the prefix matches the provided one.
 // Returns a parsed XUID if successful, or an error if either:
 // - The input cannot be parsed as a XUID
-// - The prefix doesn't match the expected value
+// - The prefix doesn't match the expected value, in which case the error is a
+// *PrefixError wrapping ErrBadPrefix and suggesting the closest registered prefixes
 //
 // This is useful for type-safe XUID parsing where the caller expects a specific entity type.
 func ParsePrefix(s, prefix string) (*XUID, error) {
@@ -137,7 +155,7 @@ func ParsePrefix(s, prefix string) (*XUID, error) {
 		return nil, err
 	}
 	if v.Prefix != prefix {
-		return nil, fmt.Errorf("%w, expected prefix %s", ErrBadPrefix, prefix)
+		return nil, &PrefixError{Got: v.Prefix, Want: prefix, Suggestions: suggestPrefix(v.Prefix)}
 	}
 	return v, nil
 }
@@ -534,6 +534,9 @@ func TestStringWithoutPrefix(t *testing.T) {
 	if !strings.HasPrefix(result, "h4nu2n-") {
 		t.Errorf("String() with empty prefix does not start with h4nu2n-, got %s", result)
 	}
+	if result != "h4nu2n-zu3f-dmnn-kguv-6f643nei" {
+		t.Errorf("String() with empty prefix = %q, want %q", result, "h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	}
 
 	// Verify that we can convert it back to a UUID
 	uuidStr := xuid.ToUUID()
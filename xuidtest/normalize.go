@@ -0,0 +1,87 @@
+package xuidtest
+
+import (
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/KarpelesLab/xuid"
+)
+
+// xuidPattern matches XUIDs in their canonical string form, with or without
+// a prefix. Matching is case-insensitive like xuid.Parse.
+var xuidPattern = regexp.MustCompile(`(?i)\b(?:[a-z0-9]{1,5}-)?[a-z2-7]{6}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{8}\b`)
+
+// Normalizer replaces XUIDs found in text with stable placeholders, so that
+// golden files stay comparable even though the IDs they contain are random.
+//
+// Each distinct XUID is replaced by its prefix followed by a sequence number
+// in angle brackets, numbered per prefix in order of first appearance: the
+// first user ID becomes user-<1>, the second distinct one user-<2>, and so
+// on. The same XUID is always replaced with the same placeholder, preserving
+// the relationships between IDs. XUIDs without a prefix become <1>, <2>...
+//
+// A Normalizer remembers the placeholders it assigned, so the same instance
+// can be used on several documents that reference each other.
+type Normalizer struct {
+	mu     sync.Mutex
+	ids    map[xuid.XUID]string
+	counts map[string]int
+}
+
+// NewNormalizer returns a new Normalizer with no assigned placeholders.
+func NewNormalizer() *Normalizer {
+	return &Normalizer{
+		ids:    make(map[xuid.XUID]string),
+		counts: make(map[string]int),
+	}
+}
+
+// Normalize returns s with all XUIDs replaced by their placeholders.
+func (n *Normalizer) Normalize(s string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return xuidPattern.ReplaceAllStringFunc(s, n.placeholder)
+}
+
+// NormalizeBytes is like Normalize but works on a byte slice, such as a JSON
+// document.
+func (n *Normalizer) NormalizeBytes(b []byte) []byte {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return xuidPattern.ReplaceAllFunc(b, func(m []byte) []byte {
+		return []byte(n.placeholder(string(m)))
+	})
+}
+
+// placeholder returns the placeholder for the XUID in s, assigning a new one
+// on first appearance. Matches that do not parse are returned unchanged.
+func (n *Normalizer) placeholder(s string) string {
+	x, err := xuid.Parse(s)
+	if err != nil {
+		return s
+	}
+	if p, ok := n.ids[*x]; ok {
+		return p
+	}
+
+	n.counts[x.Prefix]++
+	p := "<" + strconv.Itoa(n.counts[x.Prefix]) + ">"
+	if x.Prefix != "" {
+		p = x.Prefix + "-" + p
+	}
+	n.ids[*x] = p
+	return p
+}
+
+// Normalize replaces all XUIDs in s with stable placeholders using a new
+// Normalizer. See Normalizer for the placeholder format.
+func Normalize(s string) string {
+	return NewNormalizer().Normalize(s)
+}
+
+// NormalizeBytes replaces all XUIDs in b with stable placeholders using a
+// new Normalizer. See Normalizer for the placeholder format.
+func NormalizeBytes(b []byte) []byte {
+	return NewNormalizer().NormalizeBytes(b)
+}
@@ -0,0 +1,77 @@
+package xuidtest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "Prefixed IDs",
+			input: "user shell-h4nu2n-zu3f-dmnn-kguv-6f643nei owns null-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa",
+			want:  "user shell-<1> owns null-<1>",
+		},
+		{
+			name:  "Repeated and distinct IDs",
+			input: "user-h4nu2n-zu3f-dmnn-kguv-6f643nei user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa user-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+			want:  "user-<1> user-<2> user-<1>",
+		},
+		{
+			name:  "Same UUID with different prefixes",
+			input: "user-h4nu2n-zu3f-dmnn-kguv-6f643nei doc-h4nu2n-zu3f-dmnn-kguv-6f643nei",
+			want:  "user-<1> doc-<1>",
+		},
+		{
+			name:  "No prefix and uppercase",
+			input: "H4NU2N-ZU3F-DMNN-KGUV-6F643NEI and h4nu2n-zu3f-dmnn-kguv-6f643nei",
+			want:  "<1> and <1>",
+		},
+		{
+			name:  "Not an ID",
+			input: "h4nu2n-zu3f-dmnn-kguv-6f643neiextra",
+			want:  "h4nu2n-zu3f-dmnn-kguv-6f643neiextra",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	g := NewSeeded(1)
+	u1, _ := g.New("user")
+	u2, _ := g.New("user")
+	o1, _ := g.New("order")
+
+	doc, err := json.Marshal(map[string]any{
+		"id":     u1,
+		"orders": []any{map[string]any{"id": o1, "owner": u1, "reviewer": u2}},
+	})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `{"id":"user-<1>","orders":[{"id":"order-<1>","owner":"user-<1>","reviewer":"user-<2>"}]}`
+	if got := string(NormalizeBytes(doc)); got != want {
+		t.Errorf("NormalizeBytes() = %s, want %s", got, want)
+	}
+}
+
+func TestNormalizerShared(t *testing.T) {
+	n := NewNormalizer()
+	a := n.Normalize("shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	b := n.Normalize("shell-aaaaaa-aaaa-aaaa-aaaa-aaaaaaaa shell-h4nu2n-zu3f-dmnn-kguv-6f643nei")
+	if a != "shell-<1>" || b != "shell-<2> shell-<1>" {
+		t.Errorf("Normalize() = %q, %q, want shell-<1>, shell-<2> shell-<1>", a, b)
+	}
+}
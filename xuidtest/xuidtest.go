@@ -0,0 +1,118 @@
+// Package xuidtest provides helpers for testing code that uses XUIDs.
+//
+// It offers deterministic generators producing predictable IDs, a way to
+// install them as the xuid package default generator for the duration of a
+// test, and assertion helpers.
+package xuidtest
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/KarpelesLab/xuid"
+)
+
+// SequentialGenerator generates predictable XUIDs numbered from 1 for each
+// prefix, so the first "user" ID is user #1, the second user #2 and so on.
+//
+// The sequence number is stored so that it appears near the end of the
+// string form: user #1 is user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba, user #2 is
+// user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca, etc. The generated UUIDs carry no
+// version and must not be used outside of tests.
+type SequentialGenerator struct {
+	mu   sync.Mutex
+	next map[string]uint64
+}
+
+// NewSequential returns a new SequentialGenerator with all counters at zero.
+func NewSequential() *SequentialGenerator {
+	return &SequentialGenerator{next: make(map[string]uint64)}
+}
+
+// New implements xuid.Generator
+func (g *SequentialGenerator) New(prefix string) (xuid.XUID, error) {
+	g.mu.Lock()
+	g.next[prefix]++
+	n := g.next[prefix]
+	g.mu.Unlock()
+
+	return Sequential(prefix, n), nil
+}
+
+// Sequential returns the XUID that a SequentialGenerator returns as the n-th
+// ID for the given prefix. It is useful to build expected values in tests.
+// Only the lower 61 bits of n are used.
+func Sequential(prefix string, n uint64) xuid.XUID {
+	x := xuid.XUID{Prefix: prefix}
+	// the last base32 character only holds 3 bits, shift the counter past
+	// it so that it starts on a character boundary
+	binary.BigEndian.PutUint64(x.UUID[8:], n<<3)
+	return x
+}
+
+// NewSeeded returns a generator producing version 4 XUIDs from a
+// pseudo-random source initialized with seed. Two generators created with
+// the same seed return the same sequence of IDs.
+func NewSeeded(seed int64) xuid.Generator {
+	return xuid.NewRandomGenerator(xuid.WithRand(rand.New(rand.NewSource(seed))))
+}
+
+// Use installs g as the xuid package default generator and registers a
+// cleanup function restoring the previous one when the test ends. It returns
+// g for convenience.
+//
+// Since the default generator is global, tests calling Use must not run in
+// parallel with other tests creating XUIDs.
+func Use[G xuid.Generator](t testing.TB, g G) G {
+	t.Helper()
+	prev := xuid.SetDefaultGenerator(g)
+	t.Cleanup(func() {
+		xuid.SetDefaultGenerator(prev)
+	})
+	return g
+}
+
+// UseSequential installs a new SequentialGenerator for the duration of the
+// test, as with Use.
+func UseSequential(t testing.TB) *SequentialGenerator {
+	t.Helper()
+	return Use(t, NewSequential())
+}
+
+// AssertPrefix reports a test error if id does not carry the given prefix.
+// It returns true if the prefix matches.
+func AssertPrefix(t testing.TB, id xuid.XUID, prefix string) bool {
+	t.Helper()
+	if id.Prefix != prefix {
+		t.Errorf("xuid %s has prefix %q, want %q", id, id.Prefix, prefix)
+		return false
+	}
+	return true
+}
+
+// AssertEqual reports a test error if got and want are different.
+// It returns true if they are equal.
+func AssertEqual(t testing.TB, got, want xuid.XUID) bool {
+	t.Helper()
+	if !got.Equals(want) {
+		t.Errorf("xuid = %s, want %s", got, want)
+		return false
+	}
+	return true
+}
+
+// AssertParse parses s and reports a fatal test error if it is not a valid
+// XUID carrying the given prefix. An empty prefix accepts any prefix.
+func AssertParse(t testing.TB, s, prefix string) xuid.XUID {
+	t.Helper()
+	x, err := xuid.Parse(s)
+	if err != nil {
+		t.Fatalf("xuid.Parse(%q) error = %v", s, err)
+	}
+	if prefix != "" && !AssertPrefix(t, *x, prefix) {
+		t.FailNow()
+	}
+	return *x
+}
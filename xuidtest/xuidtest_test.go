@@ -0,0 +1,63 @@
+package xuidtest
+
+import (
+	"testing"
+
+	"github.com/KarpelesLab/xuid"
+)
+
+func TestSequential(t *testing.T) {
+	g := NewSequential()
+
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"user", "user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba"},
+		{"user", "user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca"},
+		{"doc", "doc-aaaaaa-aaaa-aaaa-aaaa-aaaaaaba"},
+		{"user", "user-aaaaaa-aaaa-aaaa-aaaa-aaaaaada"},
+	}
+
+	for _, tt := range tests {
+		x, err := g.New(tt.prefix)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if x.String() != tt.want {
+			t.Errorf("New(%q) = %s, want %s", tt.prefix, x, tt.want)
+		}
+	}
+
+	if s := Sequential("user", 2).String(); s != "user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca" {
+		t.Errorf("Sequential() = %s, want user-aaaaaa-aaaa-aaaa-aaaa-aaaaaaca", s)
+	}
+}
+
+func TestSeeded(t *testing.T) {
+	x1, _ := NewSeeded(7).New("user")
+	x2, _ := NewSeeded(7).New("user")
+	AssertEqual(t, x1, x2)
+	AssertPrefix(t, x1, "user")
+}
+
+func TestUse(t *testing.T) {
+	prev := xuid.DefaultGenerator()
+
+	t.Run("scoped", func(t *testing.T) {
+		UseSequential(t)
+		AssertEqual(t, *xuid.New("user"), Sequential("user", 1))
+		AssertEqual(t, *xuid.New("user"), Sequential("user", 2))
+	})
+
+	if xuid.DefaultGenerator() != prev {
+		t.Errorf("default generator was not restored after the test")
+	}
+}
+
+func TestAssertParse(t *testing.T) {
+	x := AssertParse(t, "shell-h4nu2n-zu3f-dmnn-kguv-6f643nei", "shell")
+	if x.ToUUID() != "3f1b4d37-34d9-46c6-b546-a57c5f736d22" {
+		t.Errorf("AssertParse() UUID = %s", x.ToUUID())
+	}
+}